@@ -4,3 +4,28 @@ resource "fly_volumes" "things" {
   region = "lax"
   sizegb = 10
 }
+
+resource "fly_machine" "web" {
+  app    = "getenv-terraform-provider-fly-test"
+  region = "lax"
+  image  = "nginx:latest"
+
+  guest = {
+    cpu_kind  = "shared"
+    cpus      = 1
+    memory_mb = 256
+  }
+
+  services = [
+    {
+      protocol      = "tcp"
+      internal_port = 80
+      auto_stop     = true
+      auto_start    = true
+      ports = [
+        { port = 80, handlers = ["http"], force_https = true },
+        { port = 443, handlers = ["tls", "http"] },
+      ]
+    },
+  ]
+}
@@ -4,8 +4,10 @@ go 1.19
 
 require (
 	github.com/hashicorp/terraform-plugin-framework v1.0.1
+	github.com/hashicorp/terraform-plugin-log v0.7.0
 	github.com/superfly/flyctl/api v0.0.0-20230106214612-9abbcd53108c
 	github.com/superfly/graphql v0.2.3
+	golang.org/x/time v0.3.0
 )
 
 require (
@@ -17,7 +19,6 @@ require (
 	github.com/hashicorp/go-plugin v1.4.6 // indirect
 	github.com/hashicorp/go-uuid v1.0.3 // indirect
 	github.com/hashicorp/terraform-plugin-go v0.14.2 // indirect
-	github.com/hashicorp/terraform-plugin-log v0.7.0 // indirect
 	github.com/hashicorp/terraform-registry-address v0.1.0 // indirect
 	github.com/hashicorp/terraform-svchost v0.0.0-20200729002733-f050f53b9734 // indirect
 	github.com/hashicorp/yamux v0.0.0-20180604194846-3520598351bb // indirect
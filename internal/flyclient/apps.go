@@ -0,0 +1,228 @@
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+)
+
+// App is a fly.App with the fields flyctl's api package doesn't declare
+type App struct {
+	fly.App
+	Network string
+}
+
+// appFields are the fields of an app queried by every app operation
+const appFields = `
+	id
+	name
+	status
+	deployed
+	hostname
+	appUrl
+	network
+	organization {
+		id
+		slug
+	}
+`
+
+func (c *client) GetApp(ctx context.Context, name string) (*App, error) {
+	q := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				` + appFields + `
+			}
+		}
+	`
+
+	var out struct {
+		App App
+	}
+	if err := c.runInto(ctx, q, map[string]interface{}{"appName": name}, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.App, nil
+}
+
+// GetAppDetails is GetApp with the app's current release, IP addresses,
+// certificates and regions filled in as well
+func (c *client) GetAppDetails(ctx context.Context, name string) (*App, error) {
+	q := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				` + appFields + `
+				currentRelease {
+					version
+				}
+				ipAddresses {
+					nodes {
+						id
+						address
+						type
+						region
+					}
+				}
+				sharedIpAddress
+				certificates {
+					nodes {
+						hostname
+						clientStatus
+					}
+				}
+				regions {
+					code
+				}
+			}
+		}
+	`
+
+	var out struct {
+		App App
+	}
+	if err := c.runInto(ctx, q, map[string]interface{}{"appName": name}, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.App, nil
+}
+
+// ListApps returns every app the token can see, or only those of the org
+// with the given slug when it isn't empty
+func (c *client) ListApps(ctx context.Context, orgSlug string) ([]App, error) {
+	page := `
+		apps(first: 200, after: $after) {
+			nodes {
+				` + appFields + `
+			}
+			pageInfo {
+				hasNextPage
+				endCursor
+			}
+		}
+	`
+
+	type appsPage struct {
+		Nodes    []App
+		PageInfo struct {
+			HasNextPage bool
+			EndCursor   string
+		}
+	}
+
+	var apps []App
+	var after *string
+	for {
+		var q string
+		var out struct {
+			Apps         appsPage
+			Organization struct {
+				Apps appsPage
+			}
+		}
+		vars := map[string]interface{}{"after": after}
+
+		if orgSlug != "" {
+			q = `query($slug: String!, $after: String) { organization(slug: $slug) {` + page + `} }`
+			vars["slug"] = orgSlug
+		} else {
+			q = `query($after: String) {` + page + `}`
+		}
+
+		if err := c.runInto(ctx, q, vars, &out); err != nil {
+			return nil, err
+		}
+
+		p := out.Apps
+		if orgSlug != "" {
+			p = out.Organization.Apps
+		}
+
+		apps = append(apps, p.Nodes...)
+		if !p.PageInfo.HasNextPage {
+			return apps, nil
+		}
+
+		cursor := p.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
+func (c *client) CreateApp(ctx context.Context, input fly.CreateAppInput) (*App, error) {
+	q := `
+		mutation($input: CreateAppInput!) {
+			createApp(input: $input) {
+				app {
+					` + appFields + `
+				}
+			}
+		}
+	`
+
+	var out struct {
+		CreateApp struct {
+			App App
+		}
+	}
+	if err := c.runInto(ctx, q, map[string]interface{}{"input": input}, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.CreateApp.App, nil
+}
+
+// MoveApp moves an app to another organization
+func (c *client) MoveApp(ctx context.Context, appID, orgID string) (*App, error) {
+	q := `
+		mutation($input: MoveAppInput!) {
+			moveApp(input: $input) {
+				app {
+					` + appFields + `
+				}
+			}
+		}
+	`
+
+	input := map[string]string{
+		"appId":          appID,
+		"organizationId": orgID,
+	}
+
+	var out struct {
+		MoveApp struct {
+			App App
+		}
+	}
+	if err := c.runInto(ctx, q, map[string]interface{}{"input": input}, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.MoveApp.App, nil
+}
+
+func (c *client) DeleteApp(ctx context.Context, appID string) error {
+	q := `
+		mutation($appId: ID!) {
+			deleteApp(appId: $appId) {
+				organization {
+					id
+				}
+			}
+		}
+	`
+
+	_, err := c.run(ctx, q, map[string]interface{}{"appId": appID})
+
+	return err
+}
+
+// LookupAppID looks up a Fly app by name and returns the internal ID
+func (c *client) LookupAppID(ctx context.Context, name string) (string, error) {
+	app, err := c.GetApp(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	return app.ID, nil
+}
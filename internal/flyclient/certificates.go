@@ -0,0 +1,117 @@
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+)
+
+// certificateFields are the fields of a certificate queried by every
+// certificate operation
+const certificateFields = `
+	id
+	hostname
+	clientStatus
+	configured
+	acmeDnsConfigured
+	acmeAlpnConfigured
+	dnsValidationHostname
+	dnsValidationTarget
+	isApex
+	isWildcard
+	issued {
+		nodes {
+			type
+			expiresAt
+		}
+	}
+`
+
+// GetCertificate returns the app's certificate for hostname. Its ID is empty
+// when the app has none.
+func (c *client) GetCertificate(ctx context.Context, appName, hostname string) (*fly.AppCertificate, error) {
+	q := `
+		query($appName: String!, $hostname: String!) {
+			app(name: $appName) {
+				certificate(hostname: $hostname) {
+					` + certificateFields + `
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"appName": appName, "hostname": hostname})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fq.App.Certificate, nil
+}
+
+func (c *client) AddCertificate(ctx context.Context, appID, hostname string) (*fly.AppCertificate, error) {
+	q := `
+		mutation($appId: ID!, $hostname: String!) {
+			addCertificate(appId: $appId, hostname: $hostname) {
+				certificate {
+					` + certificateFields + `
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"appId": appID, "hostname": hostname})
+	if err != nil {
+		return nil, err
+	}
+
+	return fq.AddCertificate.Certificate, nil
+}
+
+// CheckCertificate asks Fly to check the certificate's DNS again and returns
+// its updated status along with the records Fly found
+func (c *client) CheckCertificate(ctx context.Context, appID, hostname string) (*fly.AppCertificate, *fly.HostnameCheck, error) {
+	q := `
+		mutation($input: CheckCertificateInput!) {
+			checkCertificate(input: $input) {
+				certificate {
+					` + certificateFields + `
+				}
+				check {
+					aRecords
+					aaaaRecords
+					cnameRecords
+					dnsVerificationRecord
+					resolvedAddresses
+				}
+			}
+		}
+	`
+
+	input := map[string]string{
+		"appId":    appID,
+		"hostname": hostname,
+	}
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fq.CheckCertificate.Certificate, fq.CheckCertificate.Check, nil
+}
+
+func (c *client) DeleteCertificate(ctx context.Context, appID, hostname string) error {
+	q := `
+		mutation($appId: ID!, $hostname: String!) {
+			deleteCertificate(appId: $appId, hostname: $hostname) {
+				certificate {
+					id
+				}
+			}
+		}
+	`
+
+	_, err := c.run(ctx, q, map[string]interface{}{"appId": appID, "hostname": hostname})
+
+	return err
+}
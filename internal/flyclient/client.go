@@ -0,0 +1,75 @@
+// Package flyclient wraps the Fly GraphQL API in typed methods so resources
+// don't have to deal with raw queries.
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+	"github.com/superfly/graphql"
+)
+
+// Client is the part of the Fly API the provider uses. Resources depend on
+// this interface rather than on a concrete client so it can be swapped out.
+type Client interface {
+	GetApp(ctx context.Context, name string) (*App, error)
+	GetAppDetails(ctx context.Context, name string) (*App, error)
+	ListApps(ctx context.Context, orgSlug string) ([]App, error)
+	CreateApp(ctx context.Context, input fly.CreateAppInput) (*App, error)
+	MoveApp(ctx context.Context, appID, orgID string) (*App, error)
+	DeleteApp(ctx context.Context, appID string) error
+	LookupAppID(ctx context.Context, name string) (string, error)
+
+	LookupOrgID(ctx context.Context, slug string) (string, error)
+	GetOrganization(ctx context.Context, slug string) (*Organization, error)
+	ListOrganizations(ctx context.Context) ([]Organization, error)
+
+	AllocateIP(ctx context.Context, input AllocateIPInput) (*fly.IPAddress, error)
+	GetIPAddresses(ctx context.Context, appName string) ([]fly.IPAddress, error)
+	ReleaseIP(ctx context.Context, appName, id, address string) error
+
+	GetSecrets(ctx context.Context, appName string) ([]fly.Secret, error)
+	SetSecrets(ctx context.Context, input fly.SetSecretsInput) (*fly.Release, error)
+	UnsetSecrets(ctx context.Context, input fly.UnsetSecretsInput) (*fly.Release, error)
+
+	GetRegions(ctx context.Context) ([]fly.Region, error)
+	GetVMSizes(ctx context.Context) ([]fly.VMSize, error)
+
+	GetCertificate(ctx context.Context, appName, hostname string) (*fly.AppCertificate, error)
+	AddCertificate(ctx context.Context, appID, hostname string) (*fly.AppCertificate, error)
+	CheckCertificate(ctx context.Context, appID, hostname string) (*fly.AppCertificate, *fly.HostnameCheck, error)
+	DeleteCertificate(ctx context.Context, appID, hostname string) error
+
+	LookupVolumeApp(ctx context.Context, id string) (string, error)
+}
+
+var _ Client = &client{}
+
+type client struct {
+	gql *graphql.Client
+}
+
+// New returns a Client sending its requests through gql
+func New(gql *graphql.Client) Client {
+	return &client{gql: gql}
+}
+
+// run sends query with vars and decodes the response into a fly.Query
+func (c *client) run(ctx context.Context, query string, vars map[string]interface{}) (*fly.Query, error) {
+	var fq fly.Query
+	if err := c.runInto(ctx, query, vars, &fq); err != nil {
+		return nil, err
+	}
+
+	return &fq, nil
+}
+
+// runInto is run for responses fly.Query has no fields for
+func (c *client) runInto(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	grq := graphql.NewRequest(query)
+	for k, v := range vars {
+		grq.Var(k, v)
+	}
+
+	return c.gql.Run(ctx, grq, out)
+}
@@ -0,0 +1,35 @@
+package flyclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/superfly/graphql"
+)
+
+// IsNotFound reports whether err means the requested object doesn't exist,
+// whichever API it came from
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+
+	if graphql.IsNotFoundError(err) {
+		return true
+	}
+
+	// older parts of the GraphQL API only say so in the message
+	var gqlErr *graphql.GraphQLError
+	if errors.As(err, &gqlErr) {
+		msg := strings.ToLower(gqlErr.Message)
+		return strings.HasPrefix(msg, "could not find") || strings.HasPrefix(msg, "could not resolve")
+	}
+
+	return false
+}
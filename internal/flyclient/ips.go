@@ -0,0 +1,101 @@
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+)
+
+// AllocateIPInput is a fly.AllocateIPAddressInput with the network private
+// IPs are placed in, which flyctl's api package doesn't declare
+type AllocateIPInput struct {
+	fly.AllocateIPAddressInput
+	Network string `json:"network,omitempty"`
+}
+
+// AllocateIP allocates an IP address for an app. Shared IPv4 addresses have
+// no ID of their own.
+func (c *client) AllocateIP(ctx context.Context, input AllocateIPInput) (*fly.IPAddress, error) {
+	q := `
+		mutation($input: AllocateIPAddressInput!) {
+			allocateIpAddress(input: $input) {
+				app {
+					sharedIpAddress
+				}
+				ipAddress {
+					id
+					address
+					type
+					region
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Type == "shared_v4" {
+		return &fly.IPAddress{Address: fq.AllocateIPAddress.App.SharedIPAddress, Type: input.Type}, nil
+	}
+
+	return &fq.AllocateIPAddress.IPAddress, nil
+}
+
+// GetIPAddresses lists an app's IP addresses, including its shared IPv4
+// address if it has one
+func (c *client) GetIPAddresses(ctx context.Context, appName string) ([]fly.IPAddress, error) {
+	q := `
+		query($appName: String!) {
+			app(name: $appName) {
+				ipAddresses {
+					nodes {
+						id
+						address
+						type
+						region
+						createdAt
+					}
+				}
+				sharedIpAddress
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"appName": appName})
+	if err != nil {
+		return nil, err
+	}
+
+	ips := fq.App.IPAddresses.Nodes
+	if fq.App.SharedIPAddress != "" {
+		ips = append(ips, fly.IPAddress{Address: fq.App.SharedIPAddress, Type: "shared_v4"})
+	}
+
+	return ips, nil
+}
+
+// ReleaseIP releases an IP address by ID, or by address for shared IPv4
+// addresses which have no ID
+func (c *client) ReleaseIP(ctx context.Context, appName, id, address string) error {
+	q := `
+		mutation($input: ReleaseIPAddressInput!) {
+			releaseIpAddress(input: $input) {
+				clientMutationId
+			}
+		}
+	`
+
+	input := fly.ReleaseIPAddressInput{AppID: &appName}
+	if id != "" {
+		input.IPAddressID = &id
+	} else {
+		input.IP = &address
+	}
+
+	_, err := c.run(ctx, q, map[string]interface{}{"input": input})
+
+	return err
+}
@@ -0,0 +1,117 @@
+package flyclient
+
+// Types in this file mirror the JSON of the Machines REST API. They are
+// declared here rather than taken from flyctl's api package because the
+// latter lags behind the API, e.g. it has no autostop/autostart on services.
+
+type Machine struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	State      string         `json:"state"`
+	Region     string         `json:"region"`
+	InstanceID string         `json:"instance_id"`
+	PrivateIP  string         `json:"private_ip"`
+	CreatedAt  string         `json:"created_at"`
+	UpdatedAt  string         `json:"updated_at"`
+	Config     *MachineConfig `json:"config"`
+}
+
+type MachineConfig struct {
+	Image       string                  `json:"image"`
+	Env         map[string]string       `json:"env,omitempty"`
+	Metadata    map[string]string       `json:"metadata,omitempty"`
+	Guest       *MachineGuest           `json:"guest,omitempty"`
+	Services    []MachineService        `json:"services,omitempty"`
+	Checks      map[string]MachineCheck `json:"checks,omitempty"`
+	Mounts      []MachineMount          `json:"mounts,omitempty"`
+	Restart     *MachineRestart         `json:"restart,omitempty"`
+	AutoDestroy bool                    `json:"auto_destroy,omitempty"`
+}
+
+type MachineGuest struct {
+	CPUKind  string `json:"cpu_kind,omitempty"`
+	CPUs     int    `json:"cpus,omitempty"`
+	MemoryMB int    `json:"memory_mb,omitempty"`
+}
+
+type MachineService struct {
+	Protocol     string        `json:"protocol"`
+	InternalPort int           `json:"internal_port"`
+	Ports        []MachinePort `json:"ports"`
+	Autostop     *bool         `json:"autostop,omitempty"`
+	Autostart    *bool         `json:"autostart,omitempty"`
+}
+
+type MachinePort struct {
+	Port       int      `json:"port"`
+	Handlers   []string `json:"handlers,omitempty"`
+	ForceHTTPS bool     `json:"force_https,omitempty"`
+}
+
+type MachineCheck struct {
+	Type     string `json:"type"`
+	Port     int    `json:"port,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`
+	Method   string `json:"method,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+type MachineMount struct {
+	Volume string `json:"volume"`
+	Path   string `json:"path"`
+}
+
+type MachineRestart struct {
+	Policy     string `json:"policy"`
+	MaxRetries int    `json:"max_retries,omitempty"`
+}
+
+type CreateMachineRequest struct {
+	Name   string         `json:"name,omitempty"`
+	Region string         `json:"region,omitempty"`
+	Config *MachineConfig `json:"config"`
+}
+
+type MachineLease struct {
+	Nonce     string `json:"nonce"`
+	ExpiresAt int64  `json:"expires_at"`
+	Owner     string `json:"owner"`
+}
+
+type Volume struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	State             string `json:"state"`
+	SizeGB            int    `json:"size_gb"`
+	Region            string `json:"region"`
+	Zone              string `json:"zone"`
+	Encrypted         bool   `json:"encrypted"`
+	AttachedMachineID string `json:"attached_machine_id"`
+	SnapshotRetention int    `json:"snapshot_retention"`
+	CreatedAt         string `json:"created_at"`
+}
+
+type CreateVolumeRequest struct {
+	Name              string  `json:"name"`
+	Region            string  `json:"region"`
+	SizeGB            int     `json:"size_gb"`
+	Encrypted         *bool   `json:"encrypted,omitempty"`
+	RequireUniqueZone *bool   `json:"require_unique_zone,omitempty"`
+	SnapshotRetention *int    `json:"snapshot_retention,omitempty"`
+	SourceVolumeID    *string `json:"source_volume_id,omitempty"`
+	SnapshotID        *string `json:"snapshot_id,omitempty"`
+}
+
+type VolumeSnapshot struct {
+	ID            string `json:"id"`
+	Size          int64  `json:"size"`
+	Digest        string `json:"digest"`
+	Status        string `json:"status"`
+	RetentionDays int    `json:"retention_days"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type UpdateVolumeRequest struct {
+	SnapshotRetention *int `json:"snapshot_retention,omitempty"`
+}
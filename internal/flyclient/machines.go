@@ -0,0 +1,274 @@
+package flyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Machines is the part of the Machines REST API the provider uses
+type Machines interface {
+	ListMachines(ctx context.Context, app string) ([]Machine, error)
+	GetMachine(ctx context.Context, app, id string) (*Machine, error)
+	CreateMachine(ctx context.Context, app string, input CreateMachineRequest) (*Machine, error)
+	UpdateMachine(ctx context.Context, app, id, nonce string, input CreateMachineRequest) (*Machine, error)
+	DeleteMachine(ctx context.Context, app, id string, kill bool) error
+	StartMachine(ctx context.Context, app, id string) error
+	StopMachine(ctx context.Context, app, id string) error
+	WaitMachine(ctx context.Context, app, id, instanceID, state string, timeout time.Duration) error
+
+	AcquireLease(ctx context.Context, app, id string, ttl time.Duration) (*MachineLease, error)
+	ReleaseLease(ctx context.Context, app, id, nonce string) error
+
+	ListVolumes(ctx context.Context, app string) ([]Volume, error)
+	GetVolume(ctx context.Context, app, id string) (*Volume, error)
+	CreateVolume(ctx context.Context, app string, input CreateVolumeRequest) (*Volume, error)
+	UpdateVolume(ctx context.Context, app, id string, input UpdateVolumeRequest) (*Volume, error)
+	DeleteVolume(ctx context.Context, app, id string) error
+	ExtendVolume(ctx context.Context, app, id string, sizeGB int) (*Volume, error)
+
+	ListVolumeSnapshots(ctx context.Context, app, volumeID string) ([]VolumeSnapshot, error)
+	CreateVolumeSnapshot(ctx context.Context, app, volumeID string) error
+}
+
+var _ Machines = &machinesClient{}
+
+type machinesClient struct {
+	http    *http.Client
+	baseURL string
+}
+
+// APIError is returned when the Machines API answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("machines api: %d %s", e.StatusCode, e.Message)
+}
+
+// NewMachines returns a Machines client for the API at baseURL. h should use
+// the same authenticating transport as the GraphQL client.
+func NewMachines(h *http.Client, baseURL string) Machines {
+	return &machinesClient{http: h, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (c *machinesClient) ListMachines(ctx context.Context, app string) ([]Machine, error) {
+	var out []Machine
+	err := c.do(ctx, http.MethodGet, "/v1/apps/"+url.PathEscape(app)+"/machines", nil, nil, &out)
+
+	return out, err
+}
+
+func (c *machinesClient) GetMachine(ctx context.Context, app, id string) (*Machine, error) {
+	var out Machine
+	if err := c.do(ctx, http.MethodGet, machinePath(app, id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) CreateMachine(ctx context.Context, app string, input CreateMachineRequest) (*Machine, error) {
+	var out Machine
+	if err := c.do(ctx, http.MethodPost, "/v1/apps/"+url.PathEscape(app)+"/machines", nil, input, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) UpdateMachine(ctx context.Context, app, id, nonce string, input CreateMachineRequest) (*Machine, error) {
+	var out Machine
+	if err := c.do(ctx, http.MethodPost, machinePath(app, id), leaseHeader(nonce), input, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) DeleteMachine(ctx context.Context, app, id string, kill bool) error {
+	p := machinePath(app, id)
+	if kill {
+		p += "?kill=true"
+	}
+
+	return c.do(ctx, http.MethodDelete, p, nil, nil, nil)
+}
+
+func (c *machinesClient) StartMachine(ctx context.Context, app, id string) error {
+	return c.do(ctx, http.MethodPost, machinePath(app, id)+"/start", nil, nil, nil)
+}
+
+func (c *machinesClient) StopMachine(ctx context.Context, app, id string) error {
+	return c.do(ctx, http.MethodPost, machinePath(app, id)+"/stop", nil, nil, nil)
+}
+
+// WaitMachine blocks until the machine instance reaches state or the API
+// gives up after timeout, which it caps at 60 seconds
+func (c *machinesClient) WaitMachine(ctx context.Context, app, id, instanceID, state string, timeout time.Duration) error {
+	q := url.Values{}
+	q.Set("state", state)
+	q.Set("timeout", fmt.Sprint(int(timeout.Seconds())))
+	if instanceID != "" {
+		q.Set("instance_id", instanceID)
+	}
+
+	return c.do(ctx, http.MethodGet, machinePath(app, id)+"/wait?"+q.Encode(), nil, nil, nil)
+}
+
+func (c *machinesClient) AcquireLease(ctx context.Context, app, id string, ttl time.Duration) (*MachineLease, error) {
+	var out struct {
+		Data MachineLease `json:"data"`
+	}
+	p := fmt.Sprintf("%s/lease?ttl=%d", machinePath(app, id), int(ttl.Seconds()))
+	if err := c.do(ctx, http.MethodPost, p, nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.Data, nil
+}
+
+func (c *machinesClient) ReleaseLease(ctx context.Context, app, id, nonce string) error {
+	return c.do(ctx, http.MethodDelete, machinePath(app, id)+"/lease", leaseHeader(nonce), nil, nil)
+}
+
+func (c *machinesClient) ListVolumes(ctx context.Context, app string) ([]Volume, error) {
+	var out []Volume
+	err := c.do(ctx, http.MethodGet, "/v1/apps/"+url.PathEscape(app)+"/volumes", nil, nil, &out)
+
+	return out, err
+}
+
+func (c *machinesClient) GetVolume(ctx context.Context, app, id string) (*Volume, error) {
+	var out Volume
+	if err := c.do(ctx, http.MethodGet, volumePath(app, id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) CreateVolume(ctx context.Context, app string, input CreateVolumeRequest) (*Volume, error) {
+	var out Volume
+	if err := c.do(ctx, http.MethodPost, "/v1/apps/"+url.PathEscape(app)+"/volumes", nil, input, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) UpdateVolume(ctx context.Context, app, id string, input UpdateVolumeRequest) (*Volume, error) {
+	var out Volume
+	if err := c.do(ctx, http.MethodPut, volumePath(app, id), nil, input, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (c *machinesClient) DeleteVolume(ctx context.Context, app, id string) error {
+	return c.do(ctx, http.MethodDelete, volumePath(app, id), nil, nil, nil)
+}
+
+func (c *machinesClient) ExtendVolume(ctx context.Context, app, id string, sizeGB int) (*Volume, error) {
+	var out struct {
+		Volume Volume `json:"volume"`
+	}
+	in := map[string]int{"size_gb": sizeGB}
+	if err := c.do(ctx, http.MethodPut, volumePath(app, id)+"/extend", nil, in, &out); err != nil {
+		return nil, err
+	}
+
+	return &out.Volume, nil
+}
+
+func (c *machinesClient) ListVolumeSnapshots(ctx context.Context, app, volumeID string) ([]VolumeSnapshot, error) {
+	var out []VolumeSnapshot
+	err := c.do(ctx, http.MethodGet, volumePath(app, volumeID)+"/snapshots", nil, nil, &out)
+
+	return out, err
+}
+
+// CreateVolumeSnapshot starts an on-demand snapshot. The API doesn't return
+// it, it shows up in ListVolumeSnapshots instead.
+func (c *machinesClient) CreateVolumeSnapshot(ctx context.Context, app, volumeID string) error {
+	return c.do(ctx, http.MethodPost, volumePath(app, volumeID)+"/snapshots", nil, nil, nil)
+}
+
+// do sends a JSON request to the Machines API and decodes the response into
+// out when it isn't nil
+func (c *machinesClient) do(ctx context.Context, method, path string, header http.Header, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(b))
+		if json.Unmarshal(b, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+
+		return &APIError{StatusCode: res.StatusCode, Message: msg}
+	}
+
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(b, out)
+}
+
+func machinePath(app, id string) string {
+	return "/v1/apps/" + url.PathEscape(app) + "/machines/" + url.PathEscape(id)
+}
+
+func volumePath(app, id string) string {
+	return "/v1/apps/" + url.PathEscape(app) + "/volumes/" + url.PathEscape(id)
+}
+
+func leaseHeader(nonce string) http.Header {
+	if nonce == "" {
+		return nil
+	}
+
+	return http.Header{"Fly-Machine-Lease-Nonce": []string{nonce}}
+}
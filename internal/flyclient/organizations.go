@@ -0,0 +1,97 @@
+package flyclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// LookupOrgID looks up a Fly organization by slug and returns the internal ID
+func (c *client) LookupOrgID(ctx context.Context, slug string) (string, error) {
+	q := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				id
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"slug": slug})
+	if err != nil {
+		return "", err
+	}
+
+	if fq.Organization == nil {
+		return "", fmt.Errorf("organization %q not found", slug)
+	}
+
+	return fq.Organization.ID, nil
+}
+
+// Organization is a Fly organization with the fields flyctl's api package
+// doesn't declare
+type Organization struct {
+	ID            string
+	Slug          string
+	Name          string
+	Type          string
+	BillingStatus string
+	Members       struct {
+		TotalCount int
+	}
+}
+
+// organizationFields are the fields of an organization queried by every
+// organization operation
+const organizationFields = `
+	id
+	slug
+	name
+	type
+	billingStatus
+	members {
+		totalCount
+	}
+`
+
+func (c *client) GetOrganization(ctx context.Context, slug string) (*Organization, error) {
+	q := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				` + organizationFields + `
+			}
+		}
+	`
+
+	var out struct {
+		Organization *Organization
+	}
+	if err := c.runInto(ctx, q, map[string]interface{}{"slug": slug}, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Organization, nil
+}
+
+// ListOrganizations returns every organization the token can see
+func (c *client) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	q := `
+		query {
+			organizations {
+				nodes {
+					` + organizationFields + `
+				}
+			}
+		}
+	`
+
+	var out struct {
+		Organizations struct {
+			Nodes []Organization
+		}
+	}
+	if err := c.runInto(ctx, q, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Organizations.Nodes, nil
+}
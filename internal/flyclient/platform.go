@@ -0,0 +1,54 @@
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+)
+
+func (c *client) GetRegions(ctx context.Context) ([]fly.Region, error) {
+	q := `
+		query {
+			platform {
+				regions {
+					code
+					name
+					latitude
+					longitude
+					gatewayAvailable
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return fq.Platform.Regions, nil
+}
+
+func (c *client) GetVMSizes(ctx context.Context) ([]fly.VMSize, error) {
+	q := `
+		query {
+			platform {
+				vmSizes {
+					name
+					cpuCores
+					cpuClass
+					memoryMb
+					priceMonth
+					priceSecond
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return fq.Platform.VMSizes, nil
+}
@@ -0,0 +1,65 @@
+package flyclient
+
+import (
+	"context"
+
+	fly "github.com/superfly/flyctl/api"
+)
+
+func (c *client) GetSecrets(ctx context.Context, appName string) ([]fly.Secret, error) {
+	q := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				secrets {
+					name
+					digest
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"appName": appName})
+	if err != nil {
+		return nil, err
+	}
+
+	return fq.App.Secrets, nil
+}
+
+func (c *client) SetSecrets(ctx context.Context, input fly.SetSecretsInput) (*fly.Release, error) {
+	q := `
+		mutation($input: SetSecretsInput!) {
+			setSecrets(input: $input) {
+				release {
+					id
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fq.SetSecrets.Release, nil
+}
+
+func (c *client) UnsetSecrets(ctx context.Context, input fly.UnsetSecretsInput) (*fly.Release, error) {
+	q := `
+		mutation($input: UnsetSecretsInput!) {
+			unsetSecrets(input: $input) {
+				release {
+					id
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fq.UnsetSecrets.Release, nil
+}
@@ -0,0 +1,32 @@
+package flyclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// LookupVolumeApp returns the name of the app a volume belongs to, since the
+// Machines API can only address a volume through its app
+func (c *client) LookupVolumeApp(ctx context.Context, id string) (string, error) {
+	q := `
+		query($id: ID!) {
+			volume: node(id: $id) {
+				... on Volume {
+					app {
+						name
+					}
+				}
+			}
+		}
+	`
+
+	fq, err := c.run(ctx, q, map[string]interface{}{"id": id})
+	if err != nil {
+		return "", err
+	}
+	if fq.Volume.App.Name == "" {
+		return "", fmt.Errorf("volume %q not found", id)
+	}
+
+	return fq.Volume.App.Name, nil
+}
@@ -3,12 +3,12 @@ package provider
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
 )
 
 var _ datasource.DataSource = &appDataSource{}
@@ -18,11 +18,35 @@ func newAppDataSource() datasource.DataSource {
 }
 
 type appDataSource struct {
-	client *graphql.Client
+	client   flyclient.Client
+	machines flyclient.Machines
 }
 
 type appDataSourceModel struct {
-	Name types.String `tfsdk:"name"`
+	Name           types.String              `tfsdk:"name"`
+	ID             types.String              `tfsdk:"id"`
+	Org            types.String              `tfsdk:"org"`
+	Status         types.String              `tfsdk:"status"`
+	Deployed       types.Bool                `tfsdk:"deployed"`
+	Hostname       types.String              `tfsdk:"hostname"`
+	AppURL         types.String              `tfsdk:"app_url"`
+	ReleaseVersion types.Int64               `tfsdk:"release_version"`
+	IPAddresses    []appDataIPAddressModel   `tfsdk:"ip_addresses"`
+	Certificates   []appDataCertificateModel `tfsdk:"certificates"`
+	Regions        []types.String            `tfsdk:"regions"`
+	MachineCount   types.Int64               `tfsdk:"machine_count"`
+}
+
+type appDataIPAddressModel struct {
+	ID      types.String `tfsdk:"id"`
+	Address types.String `tfsdk:"address"`
+	Type    types.String `tfsdk:"type"`
+	Region  types.String `tfsdk:"region"`
+}
+
+type appDataCertificateModel struct {
+	Hostname     types.String `tfsdk:"hostname"`
+	ClientStatus types.String `tfsdk:"client_status"`
 }
 
 func (d *appDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -38,6 +62,83 @@ func (d *appDataSource) Schema(ctx context.Context, req datasource.SchemaRequest
 				MarkdownDescription: "App name",
 				Required:            true,
 			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "App ID",
+				Computed:            true,
+			},
+			"org": schema.StringAttribute{
+				MarkdownDescription: "Org slug",
+				Computed:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "App status",
+				Computed:            true,
+			},
+			"deployed": schema.BoolAttribute{
+				MarkdownDescription: "Whether the app has been deployed",
+				Computed:            true,
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "Fly hostname",
+				Computed:            true,
+			},
+			"app_url": schema.StringAttribute{
+				MarkdownDescription: "Public URL",
+				Computed:            true,
+			},
+			"release_version": schema.Int64Attribute{
+				MarkdownDescription: "Version of the current release, null before the first one",
+				Computed:            true,
+			},
+			"ip_addresses": schema.ListNestedAttribute{
+				MarkdownDescription: "Allocated IP addresses",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "IP address ID. Empty for the shared IPv4 address",
+							Computed:            true,
+						},
+						"address": schema.StringAttribute{
+							MarkdownDescription: "IP address",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "`v4`, `v6`, `private_v6` or `shared_v4`",
+							Computed:            true,
+						},
+						"region": schema.StringAttribute{
+							MarkdownDescription: "Region the address is allocated in",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"certificates": schema.ListNestedAttribute{
+				MarkdownDescription: "Certificates",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"hostname": schema.StringAttribute{
+							MarkdownDescription: "Host name",
+							Computed:            true,
+						},
+						"client_status": schema.StringAttribute{
+							MarkdownDescription: "Certificate status, `Ready` once issued",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"regions": schema.ListAttribute{
+				MarkdownDescription: "Codes of the regions the app runs in",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"machine_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of machines",
+				Computed:            true,
+			},
 		},
 	}
 }
@@ -47,18 +148,19 @@ func (d *appDataSource) Configure(ctx context.Context, req datasource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = data.client
+	d.machines = data.machines
 }
 
 func (d *appDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -70,23 +172,78 @@ func (d *appDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return
 	}
 
-	query := `
-	query ($appName: String!) {
-		app(name: $appName) {
-			name
-		}
+	fa, err := d.client.GetAppDetails(ctx, app.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	// apps not on Machines have none and only list their regions in GraphQL
+	machines, err := d.machines.ListMachines(ctx, fa.Name)
+	if err != nil && !flyclient.IsNotFound(err) {
+		resp.Diagnostics.AddError("Query failed listing machines", err.Error())
+		return
 	}
-`
 
-	r := graphql.NewRequest(query)
-	r.Var("appName", app.Name.ValueString())
+	app.Name = types.StringValue(fa.Name)
+	app.ID = types.StringValue(fa.ID)
+	app.Org = types.StringValue(fa.Organization.Slug)
+	app.Status = types.StringValue(fa.Status)
+	app.Deployed = types.BoolValue(fa.Deployed)
+	app.Hostname = types.StringValue(fa.Hostname)
+	app.AppURL = types.StringValue(fa.AppURL)
+	app.MachineCount = types.Int64Value(int64(len(machines)))
+
+	app.ReleaseVersion = types.Int64Null()
+	if fa.CurrentRelease != nil {
+		app.ReleaseVersion = types.Int64Value(int64(fa.CurrentRelease.Version))
+	}
 
-	var fr fly.Query
-	if err := d.client.Run(ctx, r, &fr); err != nil {
-		resp.Diagnostics.AddError("Query failed", err.Error())
+	app.IPAddresses = make([]appDataIPAddressModel, 0, len(fa.IPAddresses.Nodes)+1)
+	for _, ip := range fa.IPAddresses.Nodes {
+		app.IPAddresses = append(app.IPAddresses, appDataIPAddressModel{
+			ID:      types.StringValue(ip.ID),
+			Address: types.StringValue(ip.Address),
+			Type:    types.StringValue(ip.Type),
+			Region:  types.StringValue(ip.Region),
+		})
+	}
+	if fa.SharedIPAddress != "" {
+		app.IPAddresses = append(app.IPAddresses, appDataIPAddressModel{
+			ID:      types.StringValue(""),
+			Address: types.StringValue(fa.SharedIPAddress),
+			Type:    types.StringValue("shared_v4"),
+			Region:  types.StringValue(""),
+		})
 	}
 
-	app.Name = types.StringValue(fr.App.Name)
+	app.Certificates = make([]appDataCertificateModel, 0, len(fa.Certificates.Nodes))
+	for _, c := range fa.Certificates.Nodes {
+		app.Certificates = append(app.Certificates, appDataCertificateModel{
+			Hostname:     types.StringValue(c.Hostname),
+			ClientStatus: types.StringValue(c.ClientStatus),
+		})
+	}
+
+	regions := make(map[string]bool)
+	if fa.Regions != nil {
+		for _, r := range *fa.Regions {
+			regions[r.Code] = true
+		}
+	}
+	for _, m := range machines {
+		regions[m.Region] = true
+	}
+	codes := make([]string, 0, len(regions))
+	for code := range regions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	app.Regions = make([]types.String, 0, len(codes))
+	for _, code := range codes {
+		app.Regions = append(app.Regions, types.StringValue(code))
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &app)...)
 }
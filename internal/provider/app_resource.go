@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
 )
 
 var (
@@ -18,12 +21,18 @@ var (
 )
 
 type appResource struct {
-	client *graphql.Client
+	client     flyclient.Client
+	defaultOrg string
 }
 
 type appResourceModel struct {
-	Name types.String `tfsdk:"name"`
-	Org  types.String `tfsdk:"org"`
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	Org      types.String `tfsdk:"org"`
+	Network  types.String `tfsdk:"network"`
+	Status   types.String `tfsdk:"status"`
+	Hostname types.String `tfsdk:"hostname"`
+	AppURL   types.String `tfsdk:"app_url"`
 }
 
 func newAppResource() resource.Resource {
@@ -39,13 +48,57 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 		MarkdownDescription: "Fly app",
 
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "App ID",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"name": schema.StringAttribute{
 				MarkdownDescription: "App name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"org": schema.StringAttribute{
-				MarkdownDescription: "Org name",
+				MarkdownDescription: "Org slug. Defaults to the provider's `default_org`. Changing it moves the app to the new org",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"network": schema.StringAttribute{
+				MarkdownDescription: "Private network the app is attached to",
 				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "App status, e.g. `pending`, `deployed` or `suspended`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "Public hostname",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"app_url": schema.StringAttribute{
+				MarkdownDescription: "Public URL",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -56,18 +109,19 @@ func (r *appResource) Configure(_ context.Context, req resource.ConfigureRequest
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.defaultOrg = data.defaultOrg
 }
 
 func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -79,40 +133,41 @@ func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
-	q := `
-		mutation($input: CreateAppInput!) {
-			createApp(input: $input) {
-				app {
-					id
-					name
+	org := app.Org.ValueString()
+	if org == "" {
+		org = r.defaultOrg
+	}
+	if org == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("org"),
+			"Missing org",
+			"Set org on the resource or default_org on the provider.",
+		)
 
-					regions {
-						name
-						code
-					}
-				}
-			}
-		}
-	`
+		return
+	}
 
-	orgID, err := lookupOrgID(r.client, app.Org.ValueString())
+	orgID, err := r.client.LookupOrgID(ctx, org)
 	if err != nil {
 		resp.Diagnostics.AddError("Org lookup failed", err.Error())
+		return
 	}
 
 	input := fly.CreateAppInput{
 		Name:           app.Name.ValueString(),
 		OrganizationID: orgID,
 	}
+	if v := app.Network.ValueString(); v != "" {
+		input.Network = &v
+	}
 
-	grq := graphql.NewRequest(q)
-	grq.Var("input", input)
-
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
+	fa, err := r.client.CreateApp(ctx, input)
+	if err != nil {
 		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
 	}
 
+	app.update(fa)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &app)...)
 }
 
@@ -125,108 +180,93 @@ func (r *appResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	q := `
-		query ($appName: String!) {
-			app(name: $appName) {
-				id
-				name
-			}
-		}
-	`
-
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", app.Name.ValueString())
-
-	var fq fly.Query
-	if err := r.client.Run(ctx, grq, &fq); err != nil {
+	fa, err := r.client.GetApp(ctx, app.Name.ValueString())
+	if flyclient.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
 		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
 	}
 
+	app.update(fa)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &app)...)
 }
 
 func (r *appResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	resp.Diagnostics.AddError("App update not supported", "")
-}
+	var plan, state appResourceModel
 
-func (r *appResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var app appResourceModel
-
-	diags := req.State.Get(ctx, &app)
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	appID, err := lookupAppID(r.client, app.Name.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("App lookup failed", err.Error())
-	}
-
-	q := `
-		mutation($appId: ID!) {
-			deleteApp(appId: $appId) {
-				organization {
-					id
-				}
-			}
+	// name and network force a replacement so the org is the only thing
+	// that can change in place
+	if plan.Org.ValueString() != state.Org.ValueString() {
+		orgID, err := r.client.LookupOrgID(ctx, plan.Org.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Org lookup failed", err.Error())
+			return
 		}
-	`
 
-	grq := graphql.NewRequest(q)
-	grq.Var("appId", appID)
+		if _, err := r.client.MoveApp(ctx, state.ID.ValueString(), orgID); err != nil {
+			resp.Diagnostics.AddError("App move failed", err.Error())
+			return
+		}
+	}
 
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
+	fa, err := r.client.GetApp(ctx, plan.Name.ValueString())
+	if err != nil {
 		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &app)...)
+	plan.update(fa)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func (r *appResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.Diagnostics.AddError("State import not supported", "")
-}
-
-// lookupAppID looks up a Fly app by name and returns the internal ID
-func lookupAppID(client *graphql.Client, name string) (string, error) {
-	q := `
-		query ($appName: String!) {
-			app(name: $appName) {
-				id
-				name
-			}
-		}
-	`
-
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", name)
+func (r *appResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var app appResourceModel
 
-	var fq fly.Query
-	if err := client.Run(context.Background(), grq, &fq); err != nil {
-		return "", err
+	diags := req.State.Get(ctx, &app)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return fq.App.ID, nil
-}
-
-// lookupOrgID looks up a Fly organization by name and returns the internal ID
-func lookupOrgID(client *graphql.Client, name string) (string, error) {
-	q := `
-		query($slug: String!) {
-			organization(slug: $slug) {
-				id
-			}
+	appID := app.ID.ValueString()
+	if appID == "" {
+		id, err := r.client.LookupAppID(ctx, app.Name.ValueString())
+		if flyclient.IsNotFound(err) {
+			return
 		}
-	`
-
-	grq := graphql.NewRequest(q)
-	grq.Var("slug", name)
+		if err != nil {
+			resp.Diagnostics.AddError("App lookup failed", err.Error())
+			return
+		}
+		appID = id
+	}
 
-	var fq fly.Query
-	if err := client.Run(context.Background(), grq, &fq); err != nil {
-		return "", err
+	if err := r.client.DeleteApp(ctx, appID); err != nil && !flyclient.IsNotFound(err) {
+		resp.Diagnostics.AddError("Query failed", err.Error())
 	}
+}
+
+// ImportState imports an app by name
+func (r *appResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
+}
 
-	return fq.Organization.ID, nil
+// update copies the API's view of an app into the model
+func (m *appResourceModel) update(fa *flyclient.App) {
+	m.ID = types.StringValue(fa.ID)
+	m.Name = types.StringValue(fa.Name)
+	m.Org = types.StringValue(fa.Organization.Slug)
+	m.Network = types.StringValue(fa.Network)
+	m.Status = types.StringValue(fa.Status)
+	m.Hostname = types.StringValue(fa.Hostname)
+	m.AppURL = types.StringValue(fa.AppURL)
 }
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource                   = &appsDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &appsDataSource{}
+)
+
+func newAppsDataSource() datasource.DataSource {
+	return &appsDataSource{}
+}
+
+type appsDataSource struct {
+	client flyclient.Client
+}
+
+type appsDataSourceModel struct {
+	Org        types.String        `tfsdk:"org"`
+	NamePrefix types.String        `tfsdk:"name_prefix"`
+	NameRegex  types.String        `tfsdk:"name_regex"`
+	Status     types.String        `tfsdk:"status"`
+	Apps       []appsDataItemModel `tfsdk:"apps"`
+}
+
+type appsDataItemModel struct {
+	Name     types.String `tfsdk:"name"`
+	ID       types.String `tfsdk:"id"`
+	Org      types.String `tfsdk:"org"`
+	Status   types.String `tfsdk:"status"`
+	Hostname types.String `tfsdk:"hostname"`
+}
+
+func (d *appsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_apps"
+}
+
+func (d *appsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly apps the token can see, sorted by name",
+
+		Attributes: map[string]schema.Attribute{
+			"org": schema.StringAttribute{
+				MarkdownDescription: "Only list the apps of the org with this slug",
+				Optional:            true,
+			},
+			"name_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list apps whose name starts with this",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only list apps whose name matches this Go regular expression",
+				Optional:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Only list apps with this status, e.g. `deployed` or `suspended`",
+				Optional:            true,
+			},
+			"apps": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching apps",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "App name",
+							Computed:            true,
+						},
+						"id": schema.StringAttribute{
+							MarkdownDescription: "App ID",
+							Computed:            true,
+						},
+						"org": schema.StringAttribute{
+							MarkdownDescription: "Org slug",
+							Computed:            true,
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "App status",
+							Computed:            true,
+						},
+						"hostname": schema.StringAttribute{
+							MarkdownDescription: "Fly hostname",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *appsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var filter appsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &filter)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := regexp.Compile(filter.NameRegex.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "Invalid name_regex", err.Error())
+	}
+}
+
+func (d *appsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *appsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var model appsDataSourceModel
+
+	diags := req.Config.Get(ctx, &model)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	re, err := regexp.Compile(model.NameRegex.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "Invalid name_regex", err.Error())
+		return
+	}
+
+	apps, err := d.client.ListApps(ctx, model.Org.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing apps", err.Error())
+		return
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+
+	model.Apps = make([]appsDataItemModel, 0, len(apps))
+	for _, a := range apps {
+		if !strings.HasPrefix(a.Name, model.NamePrefix.ValueString()) || !re.MatchString(a.Name) {
+			continue
+		}
+		if s := model.Status.ValueString(); s != "" && !strings.EqualFold(a.Status, s) {
+			continue
+		}
+
+		model.Apps = append(model.Apps, appsDataItemModel{
+			Name:     types.StringValue(a.Name),
+			ID:       types.StringValue(a.ID),
+			Org:      types.StringValue(a.Organization.Slug),
+			Status:   types.StringValue(a.Status),
+			Hostname: types.StringValue(a.Hostname),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
@@ -2,24 +2,35 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
+)
+
+const (
+	defaultCertificateWaitTimeout = 10 * time.Minute
+	certificatePollInterval       = 10 * time.Second
 )
 
 var (
-	_ resource.Resource                = &certificatesResource{}
-	_ resource.ResourceWithConfigure   = &certificatesResource{}
-	_ resource.ResourceWithImportState = &certificatesResource{}
+	_ resource.Resource                   = &certificatesResource{}
+	_ resource.ResourceWithConfigure      = &certificatesResource{}
+	_ resource.ResourceWithImportState    = &certificatesResource{}
+	_ resource.ResourceWithValidateConfig = &certificatesResource{}
 )
 
 type certificatesResource struct {
-	client *graphql.Client
+	client flyclient.Client
 }
 
 func newCertificatesResource() resource.Resource {
@@ -27,9 +38,22 @@ func newCertificatesResource() resource.Resource {
 }
 
 type certificatesResourceModel struct {
+	ID       types.String `tfsdk:"id"`
 	AppName  types.String `tfsdk:"app"`
 	AppID    types.String `tfsdk:"app_id"`
 	HostName types.String `tfsdk:"host"`
+
+	WaitForIssuance types.Bool   `tfsdk:"wait_for_issuance"`
+	WaitTimeout     types.String `tfsdk:"wait_timeout"`
+
+	IsWildcard            types.Bool   `tfsdk:"is_wildcard"`
+	DNSValidationHostname types.String `tfsdk:"dns_validation_hostname"`
+	DNSValidationTarget   types.String `tfsdk:"dns_validation_target"`
+	AcmeDNSConfigured     types.Bool   `tfsdk:"acme_dns_configured"`
+	AcmeALPNConfigured    types.Bool   `tfsdk:"acme_alpn_configured"`
+	ClientStatus          types.String `tfsdk:"client_status"`
+	Issued                types.Bool   `tfsdk:"issued"`
+	ExpiresAt             types.String `tfsdk:"expires_at"`
 }
 
 func (r *certificatesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -41,81 +65,162 @@ func (r *certificatesResource) Schema(_ context.Context, req resource.SchemaRequ
 		MarkdownDescription: "Fly Certificates",
 
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Certificate ID",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"app": schema.StringAttribute{
 				MarkdownDescription: "App name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"app_id": schema.StringAttribute{
-				MarkdownDescription: "App ID",
-				Required:            true,
+				MarkdownDescription: "App ID. Looked up from `app` when not set, and must belong to `app` when it is",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
 			},
 			"host": schema.StringAttribute{
-				MarkdownDescription: "Host name",
-				Required:            true,
+				MarkdownDescription: "Host name. A wildcard such as `*.example.com` can only be validated through " +
+					"the `dns_validation_hostname` CNAME",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"wait_for_issuance": schema.BoolAttribute{
+				MarkdownDescription: "Wait on create until the certificate is `Ready`",
+				Optional:            true,
+			},
+			"wait_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long `wait_for_issuance` waits as a Go duration. Defaults to `10m`",
+				Optional:            true,
+			},
+			"is_wildcard": schema.BoolAttribute{
+				MarkdownDescription: "Whether the certificate is for a wildcard host",
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dns_validation_hostname": schema.StringAttribute{
+				MarkdownDescription: "Name of the CNAME record that validates the certificate over DNS, " +
+					"`_acme-challenge.` followed by the host without any wildcard",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dns_validation_target": schema.StringAttribute{
+				MarkdownDescription: "Target of the CNAME record that validates the certificate over DNS",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"acme_dns_configured": schema.BoolAttribute{
+				MarkdownDescription: "Whether the DNS validation record is in place",
+				Computed:            true,
+			},
+			"acme_alpn_configured": schema.BoolAttribute{
+				MarkdownDescription: "Whether the host points at the app so it can be validated over TLS-ALPN",
+				Computed:            true,
+			},
+			"client_status": schema.StringAttribute{
+				MarkdownDescription: "Certificate status, `Ready` once issued",
+				Computed:            true,
+			},
+			"issued": schema.BoolAttribute{
+				MarkdownDescription: "Whether a certificate has been issued",
+				Computed:            true,
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "When the first issued certificate expires",
+				Computed:            true,
 			},
 		},
 	}
 }
 
+func (r *certificatesResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var certificate certificatesResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &certificate)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if v := certificate.WaitTimeout.ValueString(); v != "" {
+		if _, err := time.ParseDuration(v); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wait_timeout"), "Invalid wait_timeout", err.Error())
+		}
+	}
+}
+
 func (r *certificatesResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
 }
 
 func (r *certificatesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var certificate certificatesResourceModel
 
 	diags := req.Plan.Get(ctx, &certificate)
-
-	data, err := json.Marshal(diags)
-	if err != nil {
-		resp.Diagnostics.AddError("unmarshall error", err.Error())
-	}
-
 	resp.Diagnostics.Append(diags...)
-
 	if resp.Diagnostics.HasError() {
-		resp.Diagnostics.AddError("Query failed setting a cert to the app", string(data))
 		return
 	}
 
-	query := `
-	mutation($appId: ID!, $hostname: String!) {
-		addCertificate(appId: $appId, hostname: $hostname) {
-			certificate {
-				hostname
-				id
-			}
-		}
+	appID, err := r.client.LookupAppID(ctx, certificate.AppName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed looking up app ID", err.Error())
+		return
 	}
-	`
+	if v := certificate.AppID.ValueString(); v != "" && v != appID {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("app_id"),
+			"Mismatched app_id",
+			fmt.Sprintf("app_id %q is not the ID of app %q, which is %q. Remove app_id to have it looked up.",
+				v, certificate.AppName.ValueString(), appID),
+		)
+		return
+	}
+	certificate.AppID = types.StringValue(appID)
 
-	appCert := fly.AppCertificate{
-		ID:       certificate.AppID.ValueString(),
-		Hostname: certificate.HostName.ValueString(),
+	cert, err := r.client.AddCertificate(ctx, appID, certificate.HostName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed setting a cert to the app", err.Error())
+		return
 	}
 
-	grq := graphql.NewRequest(query)
-	grq.Var("appId", appCert.ID)
-	grq.Var("hostname", appCert.Hostname)
+	certificate.update(cert)
 
-	var ff fly.Query
-	if err := r.client.Run(context.Background(), grq, &ff); err != nil {
-		resp.Diagnostics.AddError("Query failed setting a cert to the app", err.Error())
+	if certificate.WaitForIssuance.ValueBool() {
+		// the certificate exists either way, so it is saved even when the
+		// wait fails
+		r.wait(ctx, &certificate, resp.Diagnostics.AddError)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &certificate)...)
@@ -130,37 +235,148 @@ func (r *certificatesResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	q := `
-		query($appName: String!) {
-			appcertscompact:app(name: $appName) {
-				certificates {
-					nodes {
-						hostname
-					}
-				}
-			}
-		}
-	`
-
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", certificates.AppName.ValueString())
-
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
+	cert, err := r.client.GetCertificate(ctx, certificates.AppName.ValueString(), certificates.HostName.ValueString())
+	if flyclient.IsNotFound(err) || (err == nil && cert.ID == "") {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
 		resp.Diagnostics.AddError("Query failed fetching Read", err.Error())
+		return
 	}
 
+	certificates.update(cert)
 	resp.Diagnostics.Append(resp.State.Set(ctx, certificates)...)
-
 }
 
+// Update only happens for wait_for_issuance and wait_timeout, which don't
+// matter once the certificate exists
 func (r *certificatesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state certificatesResourceModel
 
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.WaitForIssuance = plan.WaitForIssuance
+	state.WaitTimeout = plan.WaitTimeout
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *certificatesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var certificate certificatesResourceModel
+
+	diags := req.State.Get(ctx, &certificate)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	err := r.client.DeleteCertificate(ctx, certificate.AppID.ValueString(), certificate.HostName.ValueString())
+	if err != nil && !flyclient.IsNotFound(err) {
+		resp.Diagnostics.AddError("Query failed deleting certificate", err.Error())
+	}
 }
 
+// ImportState imports a certificate by `app/hostname`, e.g.
+// `my-app/example.com`
 func (r *certificatesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	app, host, ok := strings.Cut(req.ID, "/")
+	if !ok || app == "" || host == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected app/hostname, got %q.", req.ID))
+		return
+	}
+
+	appID, err := r.client.LookupAppID(ctx, app)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed looking up app", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app"), app)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app_id"), appID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("host"), host)...)
+}
+
+// wait has Fly check the certificate until it is issued or the timeout is
+// up, then explains which DNS record is missing
+func (r *certificatesResource) wait(ctx context.Context, certificate *certificatesResourceModel, addError func(string, string)) {
+	timeout := defaultCertificateWaitTimeout
+	if v := certificate.WaitTimeout.ValueString(); v != "" {
+		timeout, _ = time.ParseDuration(v)
+	}
+	deadline := time.Now().Add(timeout)
+
+	var check *fly.HostnameCheck
+	for certificate.ClientStatus.ValueString() != "Ready" {
+		if time.Now().After(deadline) {
+			addError("Certificate not issued", certificateHint(certificate, check, timeout))
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			addError("Certificate not issued", ctx.Err().Error())
+			return
+		case <-time.After(certificatePollInterval):
+		}
+
+		cert, c, err := r.client.CheckCertificate(ctx, certificate.AppID.ValueString(), certificate.HostName.ValueString())
+		if err != nil {
+			addError("Query failed checking certificate", err.Error())
+			return
+		}
+
+		certificate.update(cert)
+		check = c
+	}
+}
+
+// certificateHint explains which DNS record keeps the certificate from
+// being issued
+func certificateHint(certificate *certificatesResourceModel, check *fly.HostnameCheck, timeout time.Duration) string {
+	host := certificate.HostName.ValueString()
+	cname := fmt.Sprintf("CNAME %s -> %s", certificate.DNSValidationHostname.ValueString(), certificate.DNSValidationTarget.ValueString())
+
+	msg := fmt.Sprintf("The certificate for %s was still %q after %s. ", host, certificate.ClientStatus.ValueString(), timeout)
+
+	switch {
+	case certificate.IsWildcard.ValueBool() && !certificate.AcmeDNSConfigured.ValueBool():
+		msg += "Wildcard certificates can only be validated over DNS, create the record " + cname + "."
+	case !certificate.AcmeDNSConfigured.ValueBool() && !certificate.AcmeALPNConfigured.ValueBool():
+		msg += "Either point " + host + " at the app with A/AAAA records, or create the record " + cname + "."
+	default:
+		msg += "The DNS records are in place, Fly may still be issuing it."
+	}
+
+	if check != nil && len(check.ResolvedAddresses) > 0 {
+		msg += fmt.Sprintf(" %s currently resolves to %s.", host, strings.Join(check.ResolvedAddresses, ", "))
+	}
+
+	return msg
+}
+
+// update copies the values Fly reports for cert into the model
+func (m *certificatesResourceModel) update(cert *fly.AppCertificate) {
+	m.ID = types.StringValue(cert.ID)
+	m.IsWildcard = types.BoolValue(cert.IsWildcard)
+	m.DNSValidationHostname = types.StringValue(cert.DNSValidationHostname)
+	m.DNSValidationTarget = types.StringValue(cert.DNSValidationTarget)
+	m.AcmeDNSConfigured = types.BoolValue(cert.AcmeDNSConfigured)
+	m.AcmeALPNConfigured = types.BoolValue(cert.AcmeALPNConfigured)
+	m.ClientStatus = types.StringValue(cert.ClientStatus)
+	m.Issued = types.BoolValue(len(cert.Issued.Nodes) > 0)
+
+	m.ExpiresAt = types.StringNull()
+	var expiresAt time.Time
+	for _, n := range cert.Issued.Nodes {
+		if expiresAt.IsZero() || n.ExpiresAt.Before(expiresAt) {
+			expiresAt = n.ExpiresAt
+		}
+	}
+	if !expiresAt.IsZero() {
+		m.ExpiresAt = types.StringValue(expiresAt.Format(time.RFC3339))
+	}
 }
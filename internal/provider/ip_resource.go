@@ -3,22 +3,33 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
 )
 
 var (
-	_ resource.Resource                = &ipResource{}
-	_ resource.ResourceWithConfigure   = &ipResource{}
-	_ resource.ResourceWithImportState = &ipResource{}
+	_ resource.Resource                   = &ipResource{}
+	_ resource.ResourceWithConfigure      = &ipResource{}
+	_ resource.ResourceWithModifyPlan     = &ipResource{}
+	_ resource.ResourceWithImportState    = &ipResource{}
+	_ resource.ResourceWithValidateConfig = &ipResource{}
 )
 
+// ipTypes are the kinds of address Fly allocates
+var ipTypes = stringOneOf{"v4", "v6", "private_v6", "shared_v4"}
+
 type ipResource struct {
-	client *graphql.Client
+	client  flyclient.Client
+	regions *regionCache
 }
 
 func newIpResource() resource.Resource {
@@ -26,8 +37,12 @@ func newIpResource() resource.Resource {
 }
 
 type ipResourceModel struct {
+	ID      types.String `tfsdk:"id"`
 	Address types.String `tfsdk:"address"`
 	AppName types.String `tfsdk:"app"`
+	Type    types.String `tfsdk:"type"`
+	Region  types.String `tfsdk:"region"`
+	Network types.String `tfsdk:"network"`
 }
 
 func (r *ipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -39,35 +54,96 @@ func (r *ipResource) Schema(_ context.Context, req resource.SchemaRequest, resp
 		MarkdownDescription: "Fly IP address",
 
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "IP address ID. Empty for shared IPv4 addresses",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"address": schema.StringAttribute{
 				MarkdownDescription: "IP address",
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"app": schema.StringAttribute{
 				MarkdownDescription: "App name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "`v4`, `v6`, `private_v6` or `shared_v4`. Defaults to `v6`",
+				Optional:            true,
+				Computed:            true,
+				Validators:          []validator.String{ipTypes},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "Region the address is allocated in. Defaults to a global address",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+			},
+			"network": schema.StringAttribute{
+				MarkdownDescription: "Network a `private_v6` address is allocated in",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 		},
 	}
 }
 
+func (r *ipResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var ip ipResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &ip)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if ip.Network.ValueString() != "" && !ip.Type.IsUnknown() && ip.Type.ValueString() != "private_v6" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("network"),
+			"Invalid network",
+			"network can only be set on private_v6 addresses.",
+		)
+	}
+}
+
 func (r *ipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.regions = data.regions
+}
+
+func (r *ipResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(validateRegionPlan(ctx, r.regions, req, "region")...)
 }
 
 func (r *ipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -79,27 +155,27 @@ func (r *ipResource) Create(ctx context.Context, req resource.CreateRequest, res
 		return
 	}
 
-	query := `
-		mutation($input: AllocateIPAddressInput!) {
-			allocateIpAddress(input: $input) {
-				ipAddress {
-					address
-				}
-			}
-		}
-	`
-
-	input := fly.AllocateIPAddressInput{AppID: ip.AppName.ValueString(), Type: "v6"}
+	ipType := ip.Type.ValueString()
+	if ipType == "" {
+		ipType = "v6"
+	}
 
-	grq := graphql.NewRequest(query)
-	grq.Var("input", input)
+	input := flyclient.AllocateIPInput{
+		AllocateIPAddressInput: fly.AllocateIPAddressInput{
+			AppID:  ip.AppName.ValueString(),
+			Type:   ipType,
+			Region: ip.Region.ValueString(),
+		},
+		Network: ip.Network.ValueString(),
+	}
 
-	var ff fly.Query
-	if err := r.client.Run(context.Background(), grq, &ff); err != nil {
+	addr, err := r.client.AllocateIP(ctx, input)
+	if err != nil {
 		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
 	}
 
-	ip.Address = types.StringValue(ff.AllocateIPAddress.IPAddress.Address)
+	ip.update(addr)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &ip)...)
 }
 
@@ -112,14 +188,64 @@ func (r *ipResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &ip)...)
+	ips, err := r.client.GetIPAddresses(ctx, ip.AppName.ValueString())
+	if flyclient.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	// imported addresses and state written before IDs were recorded only
+	// have the address to go by
+	for i := range ips {
+		if (ip.ID.ValueString() != "" && ips[i].ID == ip.ID.ValueString()) ||
+			(ip.ID.ValueString() == "" && ips[i].Address == ip.Address.ValueString()) {
+			ip.update(&ips[i])
+			resp.Diagnostics.Append(resp.State.Set(ctx, &ip)...)
+			return
+		}
+	}
+
+	resp.State.RemoveResource(ctx)
 }
 
 func (r *ipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 }
 
 func (r *ipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var ip ipResourceModel
+
+	diags := req.State.Get(ctx, &ip)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.ReleaseIP(ctx, ip.AppName.ValueString(), ip.ID.ValueString(), ip.Address.ValueString())
+	if err != nil && !flyclient.IsNotFound(err) {
+		resp.Diagnostics.AddError("Query failed releasing IP address", err.Error())
+	}
 }
 
+// ImportState imports an address by `app/address`
 func (r *ipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	app, address, ok := strings.Cut(req.ID, "/")
+	if !ok || app == "" || address == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected app/address, got %q.", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app"), app)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("address"), address)...)
+}
+
+// update copies the values Fly reports for addr into the model
+func (m *ipResourceModel) update(addr *fly.IPAddress) {
+	m.ID = types.StringValue(addr.ID)
+	m.Address = types.StringValue(addr.Address)
+	m.Type = types.StringValue(addr.Type)
+	m.Region = types.StringValue(addr.Region)
 }
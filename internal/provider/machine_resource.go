@@ -0,0 +1,731 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	machineWaitTimeout = 5 * time.Minute
+	machineLeaseTTL    = 60 * time.Second
+	// machineWaitPoll is how long a single wait asks the API to block. It
+	// stays well under the transport's per-attempt timeout so Fly's 408 gets
+	// back to us, and so a wait doesn't hold a request slot for long.
+	machineWaitPoll = 30 * time.Second
+)
+
+var (
+	_ resource.Resource                = &machineResource{}
+	_ resource.ResourceWithConfigure   = &machineResource{}
+	_ resource.ResourceWithModifyPlan  = &machineResource{}
+	_ resource.ResourceWithImportState = &machineResource{}
+)
+
+type machineResource struct {
+	machines flyclient.Machines
+	regions  *regionCache
+}
+
+func newMachineResource() resource.Resource {
+	return &machineResource{}
+}
+
+type machineResourceModel struct {
+	ID         types.String                 `tfsdk:"id"`
+	AppName    types.String                 `tfsdk:"app"`
+	Name       types.String                 `tfsdk:"name"`
+	Region     types.String                 `tfsdk:"region"`
+	Image      types.String                 `tfsdk:"image"`
+	Guest      *machineGuestModel           `tfsdk:"guest"`
+	Env        map[string]string            `tfsdk:"env"`
+	Services   []machineServiceModel        `tfsdk:"services"`
+	Checks     map[string]machineCheckModel `tfsdk:"checks"`
+	Mounts     []machineMountModel          `tfsdk:"mounts"`
+	Restart    *machineRestartModel         `tfsdk:"restart"`
+	Metadata   map[string]string            `tfsdk:"metadata"`
+	State      types.String                 `tfsdk:"state"`
+	InstanceID types.String                 `tfsdk:"instance_id"`
+	PrivateIP  types.String                 `tfsdk:"private_ip"`
+}
+
+type machineGuestModel struct {
+	CPUKind  types.String `tfsdk:"cpu_kind"`
+	CPUs     types.Int64  `tfsdk:"cpus"`
+	MemoryMB types.Int64  `tfsdk:"memory_mb"`
+}
+
+type machineServiceModel struct {
+	Protocol     types.String       `tfsdk:"protocol"`
+	InternalPort types.Int64        `tfsdk:"internal_port"`
+	Ports        []machinePortModel `tfsdk:"ports"`
+	AutoStop     types.Bool         `tfsdk:"auto_stop"`
+	AutoStart    types.Bool         `tfsdk:"auto_start"`
+}
+
+type machinePortModel struct {
+	Port       types.Int64 `tfsdk:"port"`
+	Handlers   []string    `tfsdk:"handlers"`
+	ForceHTTPS types.Bool  `tfsdk:"force_https"`
+}
+
+type machineCheckModel struct {
+	Type     types.String `tfsdk:"type"`
+	Port     types.Int64  `tfsdk:"port"`
+	Interval types.String `tfsdk:"interval"`
+	Timeout  types.String `tfsdk:"timeout"`
+	Method   types.String `tfsdk:"method"`
+	Path     types.String `tfsdk:"path"`
+}
+
+type machineMountModel struct {
+	Volume types.String `tfsdk:"volume"`
+	Path   types.String `tfsdk:"path"`
+}
+
+type machineRestartModel struct {
+	Policy     types.String `tfsdk:"policy"`
+	MaxRetries types.Int64  `tfsdk:"max_retries"`
+}
+
+func (r *machineResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine"
+}
+
+func (r *machineResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly machine",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Machine ID",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"app": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Machine name. Generated by Fly when not set",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "Deployment region. Picked by Fly when not set",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"image": schema.StringAttribute{
+				MarkdownDescription: "Docker image",
+				Required:            true,
+			},
+			"guest": schema.SingleNestedAttribute{
+				MarkdownDescription: "VM resources",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"cpu_kind": schema.StringAttribute{
+						MarkdownDescription: "`shared` or `performance`",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+					"cpus": schema.Int64Attribute{
+						MarkdownDescription: "Number of CPUs",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+					"memory_mb": schema.Int64Attribute{
+						MarkdownDescription: "Memory in MB",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+				},
+			},
+			"env": schema.MapAttribute{
+				MarkdownDescription: "Environment variables",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"services": schema.ListNestedAttribute{
+				MarkdownDescription: "Services exposed through the Fly proxy",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "`tcp` or `udp`",
+							Required:            true,
+						},
+						"internal_port": schema.Int64Attribute{
+							MarkdownDescription: "Port the machine listens on",
+							Required:            true,
+						},
+						"ports": schema.ListNestedAttribute{
+							MarkdownDescription: "Public ports",
+							Required:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"port": schema.Int64Attribute{
+										MarkdownDescription: "Public port",
+										Required:            true,
+									},
+									"handlers": schema.ListAttribute{
+										MarkdownDescription: "Handlers, e.g. `tls` and `http`",
+										ElementType:         types.StringType,
+										Optional:            true,
+									},
+									"force_https": schema.BoolAttribute{
+										MarkdownDescription: "Redirect HTTP to HTTPS",
+										Optional:            true,
+									},
+								},
+							},
+						},
+						"auto_stop": schema.BoolAttribute{
+							MarkdownDescription: "Stop the machine when the service is idle",
+							Optional:            true,
+						},
+						"auto_start": schema.BoolAttribute{
+							MarkdownDescription: "Start the machine when the service gets a request",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"checks": schema.MapNestedAttribute{
+				MarkdownDescription: "Health checks keyed by name",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "`tcp` or `http`",
+							Required:            true,
+						},
+						"port": schema.Int64Attribute{
+							MarkdownDescription: "Port to check",
+							Optional:            true,
+						},
+						"interval": schema.StringAttribute{
+							MarkdownDescription: "Time between checks, e.g. `15s`",
+							Optional:            true,
+						},
+						"timeout": schema.StringAttribute{
+							MarkdownDescription: "Check timeout, e.g. `10s`",
+							Optional:            true,
+						},
+						"method": schema.StringAttribute{
+							MarkdownDescription: "HTTP method",
+							Optional:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "HTTP path",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"mounts": schema.ListNestedAttribute{
+				MarkdownDescription: "Volumes mounted into the machine",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"volume": schema.StringAttribute{
+							MarkdownDescription: "Volume ID",
+							Required:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Mount path",
+							Required:            true,
+						},
+					},
+				},
+			},
+			"restart": schema.SingleNestedAttribute{
+				MarkdownDescription: "Restart policy",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"policy": schema.StringAttribute{
+						MarkdownDescription: "`no`, `on-failure` or `always`",
+						Required:            true,
+					},
+					"max_retries": schema.Int64Attribute{
+						MarkdownDescription: "Restarts allowed with the `on-failure` policy",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+				},
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "Machine metadata",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"state": schema.StringAttribute{
+				MarkdownDescription: "Machine state",
+				Computed:            true,
+			},
+			"instance_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the current machine version",
+				Computed:            true,
+			},
+			"private_ip": schema.StringAttribute{
+				MarkdownDescription: "Private 6PN address",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *machineResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.machines = data.machines
+	r.regions = data.regions
+}
+
+func (r *machineResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(validateRegionPlan(ctx, r.regions, req, "region")...)
+}
+
+func (r *machineResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var machine machineResourceModel
+
+	diags := req.Plan.Get(ctx, &machine)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	app := machine.AppName.ValueString()
+
+	input := flyclient.CreateMachineRequest{
+		Name:   machine.Name.ValueString(),
+		Region: machine.Region.ValueString(),
+		Config: machine.config(),
+	}
+
+	m, err := r.machines.CreateMachine(ctx, app, input)
+	if err != nil {
+		resp.Diagnostics.AddError("Machine create failed", err.Error())
+		return
+	}
+
+	machine.update(m)
+
+	// save the machine before waiting so a failed wait leaves it tainted
+	// rather than orphaned
+	resp.Diagnostics.Append(resp.State.Set(ctx, &machine)...)
+
+	if err := waitForMachine(ctx, r.machines, app, m.ID, m.InstanceID, "started"); err != nil {
+		resp.Diagnostics.AddError("Machine failed to start", err.Error())
+		return
+	}
+
+	r.refresh(ctx, &machine, resp.Diagnostics.AddError)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &machine)...)
+}
+
+func (r *machineResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var machine machineResourceModel
+
+	diags := req.State.Get(ctx, &machine)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	m, err := r.machines.GetMachine(ctx, machine.AppName.ValueString(), machine.ID.ValueString())
+	if flyclient.IsNotFound(err) || (err == nil && m.State == "destroyed") {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	machine.update(m)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &machine)...)
+}
+
+func (r *machineResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var machine machineResourceModel
+
+	diags := req.Plan.Get(ctx, &machine)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	app := machine.AppName.ValueString()
+	id := machine.ID.ValueString()
+
+	lease, err := r.machines.AcquireLease(ctx, app, id, machineLeaseTTL)
+	if err != nil {
+		resp.Diagnostics.AddError("Machine lease failed", err.Error())
+		return
+	}
+	defer func() {
+		if err := r.machines.ReleaseLease(ctx, app, id, lease.Nonce); err != nil {
+			resp.Diagnostics.AddWarning("Machine lease release failed", err.Error())
+		}
+	}()
+
+	input := flyclient.CreateMachineRequest{
+		Name:   machine.Name.ValueString(),
+		Region: machine.Region.ValueString(),
+		Config: machine.config(),
+	}
+
+	m, err := r.machines.UpdateMachine(ctx, app, id, lease.Nonce, input)
+	if err != nil {
+		resp.Diagnostics.AddError("Machine update failed", err.Error())
+		return
+	}
+
+	if err := waitForMachine(ctx, r.machines, app, id, m.InstanceID, "started"); err != nil {
+		resp.Diagnostics.AddError("Machine failed to start", err.Error())
+	}
+
+	r.refresh(ctx, &machine, resp.Diagnostics.AddError)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &machine)...)
+}
+
+func (r *machineResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var machine machineResourceModel
+
+	diags := req.State.Get(ctx, &machine)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	app := machine.AppName.ValueString()
+	id := machine.ID.ValueString()
+
+	err := r.machines.DeleteMachine(ctx, app, id, true)
+	if flyclient.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Machine delete failed", err.Error())
+		return
+	}
+
+	if err := waitForMachine(ctx, r.machines, app, id, machine.InstanceID.ValueString(), "destroyed"); err != nil {
+		resp.Diagnostics.AddError("Machine failed to stop", err.Error())
+	}
+}
+
+// ImportState imports a machine by `app/id`, e.g. `my-app/3d8d9016b5e389`.
+// guest and restart are only read back once configured, so the first apply
+// after an import sets them.
+func (r *machineResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	app, id, ok := strings.Cut(req.ID, "/")
+	if !ok || app == "" || id == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected app/id, got %q.", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app"), app)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+// refresh overwrites machine with what the Machines API reports
+func (r *machineResource) refresh(ctx context.Context, machine *machineResourceModel, addError func(string, string)) {
+	m, err := r.machines.GetMachine(ctx, machine.AppName.ValueString(), machine.ID.ValueString())
+	if err != nil {
+		addError("Query failed", err.Error())
+		return
+	}
+
+	machine.update(m)
+}
+
+// waitForMachine blocks until the machine instance reaches state. Each wait
+// gives up after machineWaitPoll so it is called again until
+// machineWaitTimeout is up.
+func waitForMachine(ctx context.Context, c flyclient.Machines, app, id, instanceID, state string) error {
+	deadline := time.Now().Add(machineWaitTimeout)
+
+	for {
+		err := c.WaitMachine(ctx, app, id, instanceID, state, machineWaitPoll)
+
+		var apiErr *flyclient.APIError
+		if err == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusRequestTimeout {
+			return err
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("machine %s did not reach state %q within %s", id, state, machineWaitTimeout)
+		}
+	}
+}
+
+// config builds the Machines API config from the model
+func (m *machineResourceModel) config() *flyclient.MachineConfig {
+	c := &flyclient.MachineConfig{
+		Image:    m.Image.ValueString(),
+		Env:      m.Env,
+		Metadata: m.Metadata,
+	}
+
+	if m.Guest != nil {
+		c.Guest = &flyclient.MachineGuest{
+			CPUKind:  m.Guest.CPUKind.ValueString(),
+			CPUs:     int(m.Guest.CPUs.ValueInt64()),
+			MemoryMB: int(m.Guest.MemoryMB.ValueInt64()),
+		}
+	}
+
+	for _, s := range m.Services {
+		svc := flyclient.MachineService{
+			Protocol:     s.Protocol.ValueString(),
+			InternalPort: int(s.InternalPort.ValueInt64()),
+			Autostop:     boolPointer(s.AutoStop),
+			Autostart:    boolPointer(s.AutoStart),
+		}
+		for _, p := range s.Ports {
+			svc.Ports = append(svc.Ports, flyclient.MachinePort{
+				Port:       int(p.Port.ValueInt64()),
+				Handlers:   p.Handlers,
+				ForceHTTPS: p.ForceHTTPS.ValueBool(),
+			})
+		}
+		c.Services = append(c.Services, svc)
+	}
+
+	if len(m.Checks) > 0 {
+		c.Checks = make(map[string]flyclient.MachineCheck, len(m.Checks))
+		for name, ch := range m.Checks {
+			c.Checks[name] = flyclient.MachineCheck{
+				Type:     ch.Type.ValueString(),
+				Port:     int(ch.Port.ValueInt64()),
+				Interval: ch.Interval.ValueString(),
+				Timeout:  ch.Timeout.ValueString(),
+				Method:   ch.Method.ValueString(),
+				Path:     ch.Path.ValueString(),
+			}
+		}
+	}
+
+	for _, mount := range m.Mounts {
+		c.Mounts = append(c.Mounts, flyclient.MachineMount{
+			Volume: mount.Volume.ValueString(),
+			Path:   mount.Path.ValueString(),
+		})
+	}
+
+	if m.Restart != nil {
+		c.Restart = &flyclient.MachineRestart{
+			Policy:     m.Restart.Policy.ValueString(),
+			MaxRetries: int(m.Restart.MaxRetries.ValueInt64()),
+		}
+	}
+
+	return c
+}
+
+// update copies the API's view of a machine into the model. Optional blocks
+// the user left out are not filled in from API defaults, and zero values the
+// API omits keep whatever the model already had, so that a refresh only
+// reports real drift.
+func (m *machineResourceModel) update(fm *flyclient.Machine) {
+	m.ID = types.StringValue(fm.ID)
+	m.Name = types.StringValue(fm.Name)
+	m.Region = types.StringValue(fm.Region)
+	m.State = types.StringValue(fm.State)
+	m.InstanceID = types.StringValue(fm.InstanceID)
+	m.PrivateIP = types.StringValue(fm.PrivateIP)
+
+	c := fm.Config
+	if c == nil {
+		return
+	}
+
+	m.Image = types.StringValue(c.Image)
+
+	if len(c.Env) > 0 || m.Env != nil {
+		m.Env = c.Env
+	}
+	if len(c.Metadata) > 0 || m.Metadata != nil {
+		m.Metadata = c.Metadata
+	}
+
+	if m.Guest != nil && c.Guest != nil {
+		m.Guest = &machineGuestModel{
+			CPUKind:  types.StringValue(c.Guest.CPUKind),
+			CPUs:     types.Int64Value(int64(c.Guest.CPUs)),
+			MemoryMB: types.Int64Value(int64(c.Guest.MemoryMB)),
+		}
+	}
+
+	if m.Restart != nil && c.Restart != nil {
+		m.Restart = &machineRestartModel{
+			Policy:     types.StringValue(c.Restart.Policy),
+			MaxRetries: types.Int64Value(int64(c.Restart.MaxRetries)),
+		}
+	}
+
+	if len(c.Services) > 0 || m.Services != nil {
+		services := make([]machineServiceModel, len(c.Services))
+		for i, s := range c.Services {
+			var prior machineServiceModel
+			if i < len(m.Services) {
+				prior = m.Services[i]
+			}
+
+			services[i] = machineServiceModel{
+				Protocol:     types.StringValue(s.Protocol),
+				InternalPort: types.Int64Value(int64(s.InternalPort)),
+				AutoStop:     mergeBoolPointer(prior.AutoStop, s.Autostop),
+				AutoStart:    mergeBoolPointer(prior.AutoStart, s.Autostart),
+			}
+
+			for j, p := range s.Ports {
+				var priorPort machinePortModel
+				if j < len(prior.Ports) {
+					priorPort = prior.Ports[j]
+				}
+
+				handlers := p.Handlers
+				if len(handlers) == 0 {
+					handlers = priorPort.Handlers
+				}
+
+				services[i].Ports = append(services[i].Ports, machinePortModel{
+					Port:       types.Int64Value(int64(p.Port)),
+					Handlers:   handlers,
+					ForceHTTPS: mergeBool(priorPort.ForceHTTPS, p.ForceHTTPS),
+				})
+			}
+		}
+		m.Services = services
+	}
+
+	if len(c.Checks) > 0 || m.Checks != nil {
+		checks := make(map[string]machineCheckModel, len(c.Checks))
+		for name, ch := range c.Checks {
+			prior := m.Checks[name]
+			checks[name] = machineCheckModel{
+				Type:     types.StringValue(ch.Type),
+				Port:     mergeInt64(prior.Port, ch.Port),
+				Interval: mergeString(prior.Interval, ch.Interval),
+				Timeout:  mergeString(prior.Timeout, ch.Timeout),
+				Method:   mergeString(prior.Method, ch.Method),
+				Path:     mergeString(prior.Path, ch.Path),
+			}
+		}
+		m.Checks = checks
+	}
+
+	if len(c.Mounts) > 0 || m.Mounts != nil {
+		mounts := make([]machineMountModel, len(c.Mounts))
+		for i, mount := range c.Mounts {
+			mounts[i] = machineMountModel{
+				Volume: types.StringValue(mount.Volume),
+				Path:   types.StringValue(mount.Path),
+			}
+		}
+		m.Mounts = mounts
+	}
+}
+
+// mergeString returns v unless it is empty, in which case prior is kept so a
+// null attribute stays null
+func mergeString(prior types.String, v string) types.String {
+	if v == "" && (prior.IsNull() || prior.ValueString() == "") {
+		return prior
+	}
+
+	return types.StringValue(v)
+}
+
+// mergeInt64 is mergeString for integers
+func mergeInt64(prior types.Int64, v int) types.Int64 {
+	if v == 0 && (prior.IsNull() || prior.ValueInt64() == 0) {
+		return prior
+	}
+
+	return types.Int64Value(int64(v))
+}
+
+// mergeBool is mergeString for booleans
+func mergeBool(prior types.Bool, v bool) types.Bool {
+	if !v && (prior.IsNull() || !prior.ValueBool()) {
+		return prior
+	}
+
+	return types.BoolValue(v)
+}
+
+// mergeBoolPointer is mergeBool for values the API may leave out entirely
+func mergeBoolPointer(prior types.Bool, v *bool) types.Bool {
+	if v == nil {
+		return prior
+	}
+
+	return types.BoolValue(*v)
+}
+
+// boolPointer returns nil for a null value so the API applies its default
+func boolPointer(v types.Bool) *bool {
+	if v.IsNull() || v.IsUnknown() {
+		return nil
+	}
+
+	b := v.ValueBool()
+	return &b
+}
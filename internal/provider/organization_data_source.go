@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &organizationDataSource{}
+
+func newOrganizationDataSource() datasource.DataSource {
+	return &organizationDataSource{}
+}
+
+type organizationDataSource struct {
+	client flyclient.Client
+}
+
+type organizationDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Slug          types.String `tfsdk:"slug"`
+	Name          types.String `tfsdk:"name"`
+	Type          types.String `tfsdk:"type"`
+	BillingStatus types.String `tfsdk:"billing_status"`
+	MemberCount   types.Int64  `tfsdk:"member_count"`
+}
+
+func (d *organizationDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_organization"
+}
+
+func (d *organizationDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly organization",
+
+		Attributes: organizationAttributes(true),
+	}
+}
+
+// organizationAttributes are the attributes of an organization, with slug
+// as the input when required is set
+func organizationAttributes(required bool) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			MarkdownDescription: "Organization ID",
+			Computed:            true,
+		},
+		"slug": schema.StringAttribute{
+			MarkdownDescription: "Organization slug",
+			Required:            required,
+			Computed:            !required,
+		},
+		"name": schema.StringAttribute{
+			MarkdownDescription: "Organization name",
+			Computed:            true,
+		},
+		"type": schema.StringAttribute{
+			MarkdownDescription: "`personal` or `shared`",
+			Computed:            true,
+		},
+		"billing_status": schema.StringAttribute{
+			MarkdownDescription: "Billing status, e.g. `current` or `past_due`",
+			Computed:            true,
+		},
+		"member_count": schema.Int64Attribute{
+			MarkdownDescription: "Number of members",
+			Computed:            true,
+		},
+	}
+}
+
+func (d *organizationDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *organizationDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var org organizationDataSourceModel
+
+	diags := req.Config.Get(ctx, &org)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	o, err := d.client.GetOrganization(ctx, org.Slug.ValueString())
+	if err == nil && o == nil {
+		resp.Diagnostics.AddError("Organization not found", fmt.Sprintf("No organization with slug %q is visible to the token.", org.Slug.ValueString()))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	org.update(o)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &org)...)
+}
+
+// update copies the values Fly reports for o into the model
+func (m *organizationDataSourceModel) update(o *flyclient.Organization) {
+	m.ID = types.StringValue(o.ID)
+	m.Slug = types.StringValue(o.Slug)
+	m.Name = types.StringValue(o.Name)
+	m.Type = types.StringValue(strings.ToLower(o.Type))
+	m.BillingStatus = types.StringValue(strings.ToLower(o.BillingStatus))
+	m.MemberCount = types.Int64Value(int64(o.Members.TotalCount))
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+)
+
+var _ datasource.DataSource = &organizationsDataSource{}
+
+func newOrganizationsDataSource() datasource.DataSource {
+	return &organizationsDataSource{}
+}
+
+type organizationsDataSource struct {
+	client flyclient.Client
+}
+
+type organizationsDataSourceModel struct {
+	Organizations []organizationDataSourceModel `tfsdk:"organizations"`
+}
+
+func (d *organizationsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_organizations"
+}
+
+func (d *organizationsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly organizations the token can see",
+
+		Attributes: map[string]schema.Attribute{
+			"organizations": schema.ListNestedAttribute{
+				MarkdownDescription: "Organizations, sorted by slug",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: organizationAttributes(false),
+				},
+			},
+		},
+	}
+}
+
+func (d *organizationsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *organizationsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	orgs, err := d.client.ListOrganizations(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing organizations", err.Error())
+		return
+	}
+
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Slug < orgs[j].Slug })
+
+	model := organizationsDataSourceModel{
+		Organizations: make([]organizationDataSourceModel, len(orgs)),
+	}
+	for i := range orgs {
+		model.Organizations[i].update(&orgs[i])
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// useStateUnlessChanged is UseStateForUnknown for computed values derived
+// from other attributes: the value is kept from state unless one of sources
+// changes, in which case it stays unknown until apply
+type useStateUnlessChanged struct {
+	sources []path.Path
+}
+
+var (
+	_ planmodifier.String = useStateUnlessChanged{}
+	_ planmodifier.Map    = useStateUnlessChanged{}
+)
+
+func (m useStateUnlessChanged) Description(_ context.Context) string {
+	names := make([]string, len(m.sources))
+	for i, p := range m.sources {
+		names[i] = p.String()
+	}
+
+	return "Keeps the value from state unless " + strings.Join(names, " or ") + " changes"
+}
+
+func (m useStateUnlessChanged) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessChanged) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+
+	if m.unchanged(ctx, req.Plan, req.State, &resp.Diagnostics) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+func (m useStateUnlessChanged) PlanModifyMap(ctx context.Context, req planmodifier.MapRequest, resp *planmodifier.MapResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+
+	if m.unchanged(ctx, req.Plan, req.State, &resp.Diagnostics) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// unchanged reports whether every source is planned to keep its value in
+// state
+func (m useStateUnlessChanged) unchanged(ctx context.Context, plan tfsdk.Plan, state tfsdk.State, diags *diag.Diagnostics) bool {
+	for _, p := range m.sources {
+		var planned, prior attr.Value
+
+		diags.Append(plan.GetAttribute(ctx, p, &planned)...)
+		diags.Append(state.GetAttribute(ctx, p, &prior)...)
+		if diags.HasError() || planned == nil || prior == nil {
+			return false
+		}
+
+		if planned.IsUnknown() || !planned.Equal(prior) {
+			return false
+		}
+	}
+
+	return true
+}
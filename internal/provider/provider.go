@@ -6,35 +6,168 @@ import (
 	"os"
 	"time"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	tfp "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/superfly/graphql"
 )
 
+const (
+	defaultGraphQLEndpoint  = "https://api.fly.io/graphql"
+	defaultMachinesEndpoint = "https://api.machines.dev"
+)
+
 var _ tfp.Provider = &provider{}
 
 type provider struct {
 	configured bool
 }
 
+type providerModel struct {
+	APIToken         types.String `tfsdk:"api_token"`
+	GraphQLEndpoint  types.String `tfsdk:"graphql_endpoint"`
+	MachinesEndpoint types.String `tfsdk:"machines_endpoint"`
+	DefaultOrg       types.String `tfsdk:"default_org"`
+	MaxRetries       types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait     types.String `tfsdk:"retry_max_wait"`
+
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+}
+
+// providerData is handed to every resource and data source as their
+// ProviderData once the provider has been configured
+type providerData struct {
+	// client talks to the GraphQL API
+	client flyclient.Client
+	// machines talks to the Machines REST API
+	machines   flyclient.Machines
+	defaultOrg string
+	// regions caches the region list used to validate region attributes
+	regions *regionCache
+}
+
 func New() tfp.Provider {
 	return &provider{}
 }
 
 func (p *provider) Configure(ctx context.Context, req tfp.ConfigureRequest, resp *tfp.ConfigureResponse) {
-	token := os.Getenv("FLY_API_TOKEN")
+	var config providerModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for attr, v := range map[string]types.String{
+		"api_token":         config.APIToken,
+		"graphql_endpoint":  config.GraphQLEndpoint,
+		"machines_endpoint": config.MachinesEndpoint,
+		"default_org":       config.DefaultOrg,
+		"retry_max_wait":    config.RetryMaxWait,
+	} {
+		if v.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Unknown provider configuration value",
+				"The provider cannot be configured with a value that is only known after apply. "+
+					"Set "+attr+" statically or through its environment variable.",
+			)
+		}
+	}
+	for attr, unknown := range map[string]bool{
+		"max_retries":             config.MaxRetries.IsUnknown(),
+		"requests_per_second":     config.RequestsPerSecond.IsUnknown(),
+		"max_concurrent_requests": config.MaxConcurrentRequests.IsUnknown(),
+	} {
+		if unknown {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Unknown provider configuration value",
+				"The provider cannot be configured with a value that is only known after apply. Set "+attr+" statically.",
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := stringValueOrEnv(config.APIToken, "FLY_API_TOKEN", "")
+	if token == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_token"),
+			"Missing Fly API token",
+			"Set api_token in the provider block or export FLY_API_TOKEN. "+
+				"A token can be created with `flyctl auth token`.",
+		)
+
+		return
+	}
+
+	maxRetries := defaultMaxRetries
+	if !config.MaxRetries.IsNull() && !config.MaxRetries.IsUnknown() {
+		maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if maxRetries < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_retries"), "Invalid max_retries", "max_retries can't be negative.")
+	}
 
-	h := http.Client{
-		Timeout:   60 * time.Second,
-		Transport: &Transport{UnderlyingTransport: http.DefaultTransport, Token: token, Ctx: ctx},
+	retryMaxWait := defaultRetryMaxWait
+	if v := config.RetryMaxWait.ValueString(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("retry_max_wait"), "Invalid retry_max_wait", err.Error())
+		}
+		retryMaxWait = d
 	}
 
-	client := graphql.NewClient("https://api.fly.io/graphql", graphql.WithHTTPClient(&h))
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	rps := float64(defaultRequestsPerSecond)
+	if !config.RequestsPerSecond.IsNull() {
+		rps = config.RequestsPerSecond.ValueFloat64()
+	}
+	if rps < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("requests_per_second"), "Invalid requests_per_second", "requests_per_second can't be negative.")
+	}
+
+	maxConcurrent := defaultMaxConcurrentRequests
+	if !config.MaxConcurrentRequests.IsNull() {
+		maxConcurrent = int(config.MaxConcurrentRequests.ValueInt64())
+	}
+	if maxConcurrent < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid max_concurrent_requests", "max_concurrent_requests can't be negative.")
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// a single transport is shared by every client so the limits hold for
+	// the whole provider no matter how many resources Terraform runs at once
+	transport := NewTransport(http.DefaultTransport, token, rps, maxConcurrent)
+	transport.MaxRetries = maxRetries
+	transport.RetryMaxWait = retryMaxWait
+
+	h := http.Client{Transport: transport}
+
+	endpoint := stringValueOrEnv(config.GraphQLEndpoint, "FLY_GRAPHQL_ENDPOINT", defaultGraphQLEndpoint)
 
-	// resp.Diagnostics.AddError("WTF", fmt.Sprintf("%T", resp.ResourceData))
+	machinesEndpoint := stringValueOrEnv(config.MachinesEndpoint, "FLY_MACHINES_ENDPOINT", defaultMachinesEndpoint)
+
+	client := flyclient.New(graphql.NewClient(endpoint, graphql.WithHTTPClient(&h)))
+
+	data := &providerData{
+		client:     client,
+		machines:   flyclient.NewMachines(&h, machinesEndpoint),
+		defaultOrg: stringValueOrEnv(config.DefaultOrg, "FLY_ORG", ""),
+		regions:    newRegionCache(client),
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
 
 	p.configured = true
 }
@@ -44,11 +177,56 @@ func (p *provider) Metadata(_ context.Context, _ tfp.MetadataRequest, resp *tfp.
 }
 
 func (p *provider) Schema(_ context.Context, _ tfp.SchemaRequest, resp *tfp.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly provider",
+
+		Attributes: map[string]schema.Attribute{
+			"api_token": schema.StringAttribute{
+				MarkdownDescription: "Fly API token. Defaults to `FLY_API_TOKEN`",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"graphql_endpoint": schema.StringAttribute{
+				MarkdownDescription: "GraphQL API endpoint. Defaults to `FLY_GRAPHQL_ENDPOINT` or " + defaultGraphQLEndpoint,
+				Optional:            true,
+			},
+			"machines_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Machines API endpoint. Defaults to `FLY_MACHINES_ENDPOINT` or " + defaultMachinesEndpoint,
+				Optional:            true,
+			},
+			"default_org": schema.StringAttribute{
+				MarkdownDescription: "Org slug used when a resource doesn't set one. Defaults to `FLY_ORG`",
+				Optional:            true,
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "Times a request failing with a 429, 5xx or connection error is retried. Defaults to 4",
+				Optional:            true,
+			},
+			"retry_max_wait": schema.StringAttribute{
+				MarkdownDescription: "Longest wait between two retries as a Go duration, e.g. `10s`. Defaults to `30s`",
+				Optional:            true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "Requests per second sent to the Fly API across all resources. `0` disables the limit. Defaults to 10",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "Requests in flight at once across all resources. `0` disables the limit. Defaults to 8",
+				Optional:            true,
+			},
+		},
+	}
 }
 
 func (p *provider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		newAppDataSource,
+		newAppsDataSource,
+		newOrganizationDataSource,
+		newOrganizationsDataSource,
+		newRegionsDataSource,
+		newVMSizesDataSource,
+		newVolumeSnapshotsDataSource,
 	}
 }
 
@@ -57,19 +235,25 @@ func (p *provider) Resources(_ context.Context) []func() resource.Resource {
 		newAppResource,
 		newIpResource,
 		newSecretsResource,
+		newSecretResource,
+		newSecretsDeploymentResource,
 		newCertificatesResource,
 		newVolumesResource,
+		newVolumeSnapshotResource,
+		newMachineResource,
 	}
 }
 
-type Transport struct {
-	UnderlyingTransport http.RoundTripper
-	Token               string
-	Ctx                 context.Context
-}
+// stringValueOrEnv returns the configured value if set, falling back to the
+// environment variable env and finally to def
+func stringValueOrEnv(v types.String, env, def string) string {
+	if !v.IsNull() && v.ValueString() != "" {
+		return v.ValueString()
+	}
 
-func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Add("Authorization", "Bearer "+t.Token)
+	if e := os.Getenv(env); e != "" {
+		return e
+	}
 
-	return t.UnderlyingTransport.RoundTrip(req)
+	return def
 }
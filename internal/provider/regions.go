@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	fly "github.com/superfly/flyctl/api"
+)
+
+// regionCache fetches Fly's regions once per provider so every resource
+// validating a region during a plan shares a single request
+type regionCache struct {
+	client flyclient.Client
+
+	mu      sync.Mutex
+	regions []fly.Region
+}
+
+func newRegionCache(client flyclient.Client) *regionCache {
+	return &regionCache{client: client}
+}
+
+// get returns the regions, fetching them on first use. Failures, and an
+// empty list that would reject every region, aren't cached so the next
+// caller tries again.
+func (c *regionCache) get(ctx context.Context) ([]fly.Region, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.regions != nil {
+		return c.regions, nil
+	}
+
+	regions, err := c.client.GetRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, errors.New("the API returned no regions")
+	}
+	c.regions = regions
+
+	return regions, nil
+}
+
+// validateRegionPlan checks the planned region at attr against Fly's
+// regions. It is called from ModifyPlan since validators run before the
+// provider is configured and so can't reach the API. Regions already in
+// state are left alone so a region Fly retires doesn't break existing
+// resources.
+func validateRegionPlan(ctx context.Context, cache *regionCache, req resource.ModifyPlanRequest, attr string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if cache == nil || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var planned, prior types.String
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root(attr), &planned)...)
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root(attr), &prior)...)
+	}
+	if diags.HasError() || planned.IsUnknown() || planned.ValueString() == "" || planned.Equal(prior) {
+		return diags
+	}
+
+	regions, err := cache.get(ctx)
+	if err != nil {
+		diags.AddAttributeWarning(path.Root(attr), "Couldn't validate region", err.Error())
+		return diags
+	}
+
+	codes := make([]string, 0, len(regions))
+	for _, r := range regions {
+		if r.Code == planned.ValueString() {
+			return diags
+		}
+		codes = append(codes, r.Code)
+	}
+	sort.Strings(codes)
+
+	diags.AddAttributeError(
+		path.Root(attr),
+		"Unknown region",
+		fmt.Sprintf("%q is not a Fly region. Valid regions are %s.", planned.ValueString(), strings.Join(codes, ", ")),
+	)
+
+	return diags
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &regionsDataSource{}
+
+func newRegionsDataSource() datasource.DataSource {
+	return &regionsDataSource{}
+}
+
+type regionsDataSource struct {
+	regions *regionCache
+}
+
+type regionsDataSourceModel struct {
+	Regions []regionDataItemModel `tfsdk:"regions"`
+}
+
+type regionDataItemModel struct {
+	Code             types.String  `tfsdk:"code"`
+	Name             types.String  `tfsdk:"name"`
+	GatewayAvailable types.Bool    `tfsdk:"gateway_available"`
+	Latitude         types.Float64 `tfsdk:"latitude"`
+	Longitude        types.Float64 `tfsdk:"longitude"`
+}
+
+func (d *regionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_regions"
+}
+
+func (d *regionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly regions",
+
+		Attributes: map[string]schema.Attribute{
+			"regions": schema.ListNestedAttribute{
+				MarkdownDescription: "Regions, sorted by code",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"code": schema.StringAttribute{
+							MarkdownDescription: "Region code, e.g. `lax`",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Region name",
+							Computed:            true,
+						},
+						"gateway_available": schema.BoolAttribute{
+							MarkdownDescription: "Whether WireGuard gateways can be created in the region",
+							Computed:            true,
+						},
+						"latitude": schema.Float64Attribute{
+							MarkdownDescription: "Latitude",
+							Computed:            true,
+						},
+						"longitude": schema.Float64Attribute{
+							MarkdownDescription: "Longitude",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *regionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.regions = data.regions
+}
+
+func (d *regionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	regions, err := d.regions.get(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing regions", err.Error())
+		return
+	}
+
+	model := regionsDataSourceModel{
+		Regions: make([]regionDataItemModel, 0, len(regions)),
+	}
+	for _, r := range regions {
+		model.Regions = append(model.Regions, regionDataItemModel{
+			Code:             types.StringValue(r.Code),
+			Name:             types.StringValue(r.Name),
+			GatewayAvailable: types.BoolValue(r.GatewayAvailable),
+			Latitude:         types.Float64Value(float64(r.Latitude)),
+			Longitude:        types.Float64Value(float64(r.Longitude)),
+		})
+	}
+	sort.Slice(model.Regions, func(i, j int) bool {
+		return model.Regions[i].Code.ValueString() < model.Regions[j].Code.ValueString()
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	fly "github.com/superfly/flyctl/api"
+)
+
+var (
+	_ resource.Resource                = &secretResource{}
+	_ resource.ResourceWithConfigure   = &secretResource{}
+	_ resource.ResourceWithImportState = &secretResource{}
+)
+
+type secretResource struct {
+	client   flyclient.Client
+	machines flyclient.Machines
+}
+
+func newSecretResource() resource.Resource {
+	return &secretResource{}
+}
+
+type secretResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	AppName types.String `tfsdk:"app"`
+	Key     types.String `tfsdk:"key"`
+	Value   types.String `tfsdk:"value"`
+	Digest  types.String `tfsdk:"digest"`
+	Deploy  types.Bool   `tfsdk:"deploy"`
+}
+
+func (r *secretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secret"
+}
+
+func (r *secretResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "A single Fly secret. Several modules can each own the secrets they produce. " +
+			"A key must be managed either here or in a `fly_secrets` map, never both: `fly_secrets` " +
+			"only touches the keys in its own map so the two coexist as long as their keys don't overlap.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "`app/key`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"app": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Secret name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.StringAttribute{
+				MarkdownDescription: "Secret value. Fly never returns it, so it is only ever written; " +
+					"changes made outside Terraform are found through `digest`",
+				Required:  true,
+				Sensitive: true,
+			},
+			"digest": schema.StringAttribute{
+				MarkdownDescription: "Digest Fly reports for the secret",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					useStateUnlessChanged{sources: []path.Path{path.Root("app"), path.Root("value")}},
+				},
+			},
+			"deploy": schema.BoolAttribute{
+				MarkdownDescription: "Restart the app's running machines one at a time after every change so they " +
+					"pick the new values up. Each resource with `deploy` restarts them on its own, so when several " +
+					"change in one apply leave this off and add a `fly_secrets_deployment` instead. Apps not on " +
+					"Machines are always released by Fly",
+				Optional: true,
+			},
+		},
+	}
+}
+
+func (r *secretResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.client
+	r.machines = data.machines
+}
+
+func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var secret secretResourceModel
+
+	diags := req.Plan.Get(ctx, &secret)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	secret.ID = types.StringValue(secret.AppName.ValueString() + "/" + secret.Key.ValueString())
+
+	// a failed deploy still leaves the secret set, so it is saved as long
+	// as the write itself went through
+	r.set(ctx, &secret, resp.Diagnostics.AddError)
+	if secret.Digest.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &secret)...)
+}
+
+func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var secret secretResourceModel
+
+	diags := req.State.Get(ctx, &secret)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	digest, found, err := r.digest(ctx, secret.AppName.ValueString(), secret.Key.ValueString())
+	if flyclient.IsNotFound(err) || (err == nil && !found) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	// the value was changed outside Terraform: forget it so the configured
+	// value gets set again
+	if secret.Digest.ValueString() != digest {
+		secret.Value = types.StringNull()
+	}
+	secret.Digest = types.StringValue(digest)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &secret)...)
+}
+
+func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var secret, prior secretResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &secret)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if secret.Value.Equal(prior.Value) {
+		// only deploy changed
+		secret.Digest = prior.Digest
+	} else {
+		r.set(ctx, &secret, resp.Diagnostics.AddError)
+		if secret.Digest.IsUnknown() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &secret)...)
+}
+
+func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var secret secretResourceModel
+
+	diags := req.State.Get(ctx, &secret)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	input := fly.UnsetSecretsInput{
+		AppID: secret.AppName.ValueString(),
+		Keys:  []string{secret.Key.ValueString()},
+	}
+
+	_, err := r.client.UnsetSecrets(ctx, input)
+	if flyclient.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed on destroy", err.Error())
+		return
+	}
+
+	r.deploy(ctx, &secret, resp.Diagnostics.AddError)
+}
+
+// ImportState imports a secret by `app/key`. The value can't be read back so
+// the next apply sets it again.
+func (r *secretResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	app, key, ok := strings.Cut(req.ID, "/")
+	if !ok || app == "" || key == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected app/key, got %q.", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app"), app)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), key)...)
+}
+
+// set writes the secret's value and records the digest Fly reports for it
+func (r *secretResource) set(ctx context.Context, secret *secretResourceModel, addError func(string, string)) {
+	input := fly.SetSecretsInput{
+		AppID: secret.AppName.ValueString(),
+		Secrets: []fly.SetSecretsInputSecret{
+			{Key: secret.Key.ValueString(), Value: secret.Value.ValueString()},
+		},
+	}
+
+	if _, err := r.client.SetSecrets(ctx, input); err != nil {
+		addError("Query failed", err.Error())
+		return
+	}
+
+	digest, _, err := r.digest(ctx, secret.AppName.ValueString(), secret.Key.ValueString())
+	if err != nil {
+		addError("Query failed fetching digest", err.Error())
+		return
+	}
+
+	secret.Digest = types.StringValue(digest)
+
+	r.deploy(ctx, secret, addError)
+}
+
+// deploy restarts the app's machines if the secret is deployed on change
+func (r *secretResource) deploy(ctx context.Context, secret *secretResourceModel, addError func(string, string)) {
+	if !secret.Deploy.ValueBool() {
+		return
+	}
+
+	if err := deploySecrets(ctx, r.machines, secret.AppName.ValueString()); err != nil {
+		addError("Secrets deployment failed", err.Error())
+	}
+}
+
+// digest returns the digest of the app's secret key and whether it exists
+func (r *secretResource) digest(ctx context.Context, app, key string) (string, bool, error) {
+	secrets, err := r.client.GetSecrets(ctx, app)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, s := range secrets {
+		if s.Name == key {
+			return s.Digest, true, nil
+		}
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource              = &secretsDeploymentResource{}
+	_ resource.ResourceWithConfigure = &secretsDeploymentResource{}
+)
+
+type secretsDeploymentResource struct {
+	machines flyclient.Machines
+}
+
+func newSecretsDeploymentResource() resource.Resource {
+	return &secretsDeploymentResource{}
+}
+
+type secretsDeploymentResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	AppName  types.String `tfsdk:"app"`
+	Triggers types.Map    `tfsdk:"triggers"`
+}
+
+func (r *secretsDeploymentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secrets_deployment"
+}
+
+func (r *secretsDeploymentResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Deploys an app's secrets by restarting its running machines once, one at a time. " +
+			"Point `triggers` at the `digests`/`digest` of the app's secrets resources so it runs whenever " +
+			"one of them changes.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"app": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				MarkdownDescription: "Any change to these values deploys the secrets again",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *secretsDeploymentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.machines = data.machines
+}
+
+func (r *secretsDeploymentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var deployment secretsDeploymentResourceModel
+
+	diags := req.Plan.Get(ctx, &deployment)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := deploySecrets(ctx, r.machines, deployment.AppName.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Secrets deployment failed", err.Error())
+		return
+	}
+
+	deployment.ID = deployment.AppName
+	resp.Diagnostics.Append(resp.State.Set(ctx, &deployment)...)
+}
+
+func (r *secretsDeploymentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+}
+
+func (r *secretsDeploymentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+}
+
+func (r *secretsDeploymentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// deploySecrets restarts the app's running machines one at a time so they
+// pick up the app's current secrets. Stopped machines load them on their
+// next start.
+func deploySecrets(ctx context.Context, c flyclient.Machines, app string) error {
+	machines, err := c.ListMachines(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range machines {
+		if m.State != "started" {
+			continue
+		}
+
+		// a restart keeps the instance ID, so waiting for "started" after one
+		// returns straight away. Stopping first lets each wait see the change
+		// and keeps only one machine down at a time.
+		if err := c.StopMachine(ctx, app, m.ID); err != nil {
+			return fmt.Errorf("stopping machine %s: %w", m.ID, err)
+		}
+		if err := waitForMachine(ctx, c, app, m.ID, m.InstanceID, "stopped"); err != nil {
+			return err
+		}
+
+		if err := c.StartMachine(ctx, app, m.ID); err != nil {
+			return fmt.Errorf("starting machine %s: %w", m.ID, err)
+		}
+		if err := waitForMachine(ctx, c, app, m.ID, m.InstanceID, "started"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
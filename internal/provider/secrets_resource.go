@@ -3,12 +3,17 @@ package provider
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
 )
 
 var (
@@ -18,7 +23,8 @@ var (
 )
 
 type secretsResource struct {
-	client *graphql.Client
+	client   flyclient.Client
+	machines flyclient.Machines
 }
 
 func newSecretsResource() resource.Resource {
@@ -28,6 +34,8 @@ func newSecretsResource() resource.Resource {
 type secretsResourceModel struct {
 	AppName types.String `tfsdk:"app"`
 	Secrets types.Map    `tfsdk:"secrets"`
+	Digests types.Map    `tfsdk:"digests"`
+	Deploy  types.Bool   `tfsdk:"deploy"`
 }
 
 func (r *secretsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -42,10 +50,30 @@ func (r *secretsResource) Schema(_ context.Context, req resource.SchemaRequest,
 			"app": schema.StringAttribute{
 				MarkdownDescription: "App name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"secrets": schema.MapAttribute{
-				ElementType: types.StringType,
-				Required:    true,
+				MarkdownDescription: "Secret values keyed by name",
+				ElementType:         types.StringType,
+				Required:            true,
+				Sensitive:           true,
+			},
+			"digests": schema.MapAttribute{
+				MarkdownDescription: "Digests Fly reports for each secret, used to detect changes made outside Terraform",
+				ElementType:         types.StringType,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Map{
+					useStateUnlessChanged{sources: []path.Path{path.Root("app"), path.Root("secrets")}},
+				},
+			},
+			"deploy": schema.BoolAttribute{
+				MarkdownDescription: "Restart the app's running machines one at a time after every change so they " +
+					"pick the new values up. Each resource with `deploy` restarts them on its own, so when several " +
+					"change in one apply leave this off and add a `fly_secrets_deployment` instead. Apps not on " +
+					"Machines are always released by Fly",
+				Optional: true,
 			},
 		},
 	}
@@ -56,18 +84,19 @@ func (r *secretsResource) Configure(_ context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.machines = data.machines
 }
 
 func (r *secretsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -79,16 +108,6 @@ func (r *secretsResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	query := `
-	mutation($input: SetSecretsInput!) {
-		setSecrets(input: $input) {
-			release {
-				id
-			}
-		}
-	}
-	`
-
 	input := fly.SetSecretsInput{AppID: secrets.AppName.ValueString()}
 
 	var secretKvs = make(map[string]string)
@@ -101,14 +120,14 @@ func (r *secretsResource) Create(ctx context.Context, req resource.CreateRequest
 		})
 	}
 
-	grq := graphql.NewRequest(query)
-	grq.Var("input", input)
-
-	var ff fly.Query
-	if err := r.client.Run(context.Background(), grq, &ff); err != nil {
+	if _, err := r.client.SetSecrets(ctx, input); err != nil {
 		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
 	}
 
+	r.deploy(ctx, &secrets, resp.Diagnostics.AddError)
+
+	resp.Diagnostics.Append(r.setDigests(ctx, &secrets, secretKvs)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &secrets)...)
 }
 
@@ -121,71 +140,103 @@ func (r *secretsResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	q := `
-		query ($appName: String!) {
-			app(name: $appName) {
-				secrets {
-					name
-					digest
-				}
-			}
-		}
-	`
+	remote, err := r.client.GetSecrets(ctx, secrets.AppName.ValueString())
+	if flyclient.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed", err.Error())
+		return
+	}
+
+	remoteDigests := make(map[string]string, len(remote))
+	for _, s := range remote {
+		remoteDigests[s.Name] = s.Digest
+	}
 
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", secrets.AppName.ValueString())
+	var secretKvs = make(map[string]string)
+	resp.Diagnostics.Append(secrets.Secrets.ElementsAs(ctx, &secretKvs, false)...)
 
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
-		resp.Diagnostics.AddError("Query failed", err.Error())
+	var digests = make(map[string]string)
+	if !secrets.Digests.IsNull() {
+		resp.Diagnostics.Append(secrets.Digests.ElementsAs(ctx, &digests, false)...)
 	}
 
-	// FIX: set secrets kv pairs
+	// Secret values can't be read back, so a key that was unset or whose
+	// digest changed since we last set it is dropped from state. Terraform
+	// then plans to set it again.
+	for k := range secretKvs {
+		d, ok := remoteDigests[k]
+		if !ok || (digests[k] != "" && digests[k] != d) {
+			delete(secretKvs, k)
+			delete(digests, k)
+			continue
+		}
+		digests[k] = d
+	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, secrets)...)
+	secrets.Secrets, diags = types.MapValueFrom(ctx, types.StringType, secretKvs)
+	resp.Diagnostics.Append(diags...)
+	secrets.Digests, diags = types.MapValueFrom(ctx, types.StringType, digests)
+	resp.Diagnostics.Append(diags...)
 
+	resp.Diagnostics.Append(resp.State.Set(ctx, secrets)...)
 }
 
 func (r *secretsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var secrets, prior secretsResourceModel
 
-	var secrets secretsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &secrets)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var secretKvs = make(map[string]string)
+	resp.Diagnostics.Append(secrets.Secrets.ElementsAs(ctx, &secretKvs, false)...)
+
+	var priorKvs = make(map[string]string)
+	resp.Diagnostics.Append(prior.Secrets.ElementsAs(ctx, &priorKvs, false)...)
 
-	diags := req.Plan.Get(ctx, &secrets)
-	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	query := `
-	mutation($input: SetSecretsInput!) {
-		setSecrets(input: $input) {
-			release {
-				id
-			}
+	set, unset := diffSecrets(priorKvs, secretKvs)
+
+	// The API has no single mutation that both sets and unsets keys, so an
+	// apply doing both creates two releases. Setting goes first so that a
+	// failure leaves the app with extra secrets rather than missing ones.
+	if len(set) > 0 {
+		input := fly.SetSecretsInput{AppID: secrets.AppName.ValueString()}
+		for _, k := range sortedKeys(set) {
+			input.Secrets = append(input.Secrets, fly.SetSecretsInputSecret{
+				Key:   k,
+				Value: set[k],
+			})
 		}
-	}
-	`
 
-	input := fly.SetSecretsInput{AppID: secrets.AppName.ValueString()}
+		if _, err := r.client.SetSecrets(ctx, input); err != nil {
+			resp.Diagnostics.AddError("Query failed", "client interaction:"+err.Error())
+			return
+		}
+	}
 
-	var secretKvs = make(map[string]string)
-	secrets.Secrets.ElementsAs(ctx, &secretKvs, false)
+	if len(unset) > 0 {
+		input := fly.UnsetSecretsInput{AppID: secrets.AppName.ValueString(), Keys: unset}
 
-	for k, v := range secretKvs {
-		input.Secrets = append(input.Secrets, fly.SetSecretsInputSecret{
-			Key:   k,
-			Value: v,
-		})
+		if _, err := r.client.UnsetSecrets(ctx, input); err != nil {
+			resp.Diagnostics.AddError("Query failed unsetting removed secrets", err.Error())
+			return
+		}
 	}
 
-	grq := graphql.NewRequest(query)
-	grq.Var("input", input)
-
-	var ff fly.Query
-	if err := r.client.Run(context.Background(), grq, &ff); err != nil {
-		resp.Diagnostics.AddError("Query failed", "client interaction:"+err.Error())
+	if len(set) > 0 || len(unset) > 0 {
+		r.deploy(ctx, &secrets, resp.Diagnostics.AddError)
 	}
 
+	resp.Diagnostics.Append(r.setDigests(ctx, &secrets, secretKvs)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &secrets)...)
 }
 
@@ -198,16 +249,6 @@ func (r *secretsResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	q := `
-	mutation($input: UnsetSecretsInput!) {
-		unsetSecrets(input: $input) {
-			release {
-				id
-			}
-		}
-	}
-	`
-
 	var secretKvs = make(map[string]string)
 	secrets.Secrets.ElementsAs(ctx, &secretKvs, false)
 
@@ -218,17 +259,79 @@ func (r *secretsResource) Delete(ctx context.Context, req resource.DeleteRequest
 
 	input := fly.UnsetSecretsInput{AppID: secrets.AppName.ValueString(), Keys: keys}
 
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", secrets.AppName.ValueString())
-	grq.Var("input", input)
-
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
+	if _, err := r.client.UnsetSecrets(ctx, input); err != nil {
 		resp.Diagnostics.AddError("Query failed on destroy", err.Error())
+		return
 	}
 
+	r.deploy(ctx, &secrets, resp.Diagnostics.AddError)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, secrets)...)
 }
 
 func (r *secretsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 }
+
+// deploy restarts the app's machines if the secrets are deployed on change
+func (r *secretsResource) deploy(ctx context.Context, secrets *secretsResourceModel, addError func(string, string)) {
+	if !secrets.Deploy.ValueBool() {
+		return
+	}
+
+	if err := deploySecrets(ctx, r.machines, secrets.AppName.ValueString()); err != nil {
+		addError("Secrets deployment failed", err.Error())
+	}
+}
+
+// setDigests records the digests Fly now reports for the keys in kvs
+func (r *secretsResource) setDigests(ctx context.Context, secrets *secretsResourceModel, kvs map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	remote, err := r.client.GetSecrets(ctx, secrets.AppName.ValueString())
+	if err != nil {
+		secrets.Digests = types.MapNull(types.StringType)
+		diags.AddError("Query failed fetching digests", err.Error())
+		return diags
+	}
+
+	digests := make(map[string]string, len(kvs))
+	for _, s := range remote {
+		if _, ok := kvs[s.Name]; ok {
+			digests[s.Name] = s.Digest
+		}
+	}
+
+	secrets.Digests, diags = types.MapValueFrom(ctx, types.StringType, digests)
+
+	return diags
+}
+
+// diffSecrets returns the keys of planned that are new or changed since
+// prior, and the sorted keys of prior that are gone from planned
+func diffSecrets(prior, planned map[string]string) (map[string]string, []string) {
+	set := make(map[string]string)
+	for k, v := range planned {
+		if pv, ok := prior[k]; !ok || pv != v {
+			set[k] = v
+		}
+	}
+
+	var unset []string
+	for _, k := range sortedKeys(prior) {
+		if _, ok := planned[k]; !ok {
+			unset = append(unset, k)
+		}
+	}
+
+	return set, unset
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,428 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxRetries     = 4
+	defaultRetryMaxWait   = 30 * time.Second
+	defaultAttemptTimeout = 60 * time.Second
+
+	defaultRequestsPerSecond     = 10
+	defaultMaxConcurrentRequests = 8
+	retryBaseWait                = 500 * time.Millisecond
+)
+
+// safeMutations are mutations that converge on the same end state no matter
+// how many times they are sent, so they can be retried like queries
+var safeMutations = map[string]bool{
+	"setSecrets":   true,
+	"unsetSecrets": true,
+}
+
+// operationRe captures the operation type and its first field, skipping an
+// optional alias
+var operationRe = regexp.MustCompile(`^(query|mutation)?[^{]*\{\s*(?:\w+\s*:\s*)?(\w+)`)
+
+// Transport authenticates every request against the Fly API, throttles
+// requests so the provider stays under Fly's rate limits and retries
+// transient failures with exponential backoff
+type Transport struct {
+	UnderlyingTransport http.RoundTripper
+	Token               string
+
+	// MaxRetries is the number of times a failed request is retried
+	MaxRetries int
+	// RetryMaxWait caps the wait between two attempts
+	RetryMaxWait time.Duration
+	// Timeout bounds a single attempt, including reading the response body
+	Timeout time.Duration
+
+	// Limiter, when set, is waited on before every attempt
+	Limiter *rate.Limiter
+	// Slots, when set, bounds the number of requests in flight. A slot is
+	// held until the response body is closed.
+	Slots chan struct{}
+}
+
+// NewTransport returns a Transport sending at most rps requests per second
+// and maxConcurrent requests at once. A zero value disables either limit.
+func NewTransport(underlying http.RoundTripper, token string, rps float64, maxConcurrent int) *Transport {
+	t := &Transport{
+		UnderlyingTransport: underlying,
+		Token:               token,
+		MaxRetries:          defaultMaxRetries,
+		RetryMaxWait:        defaultRetryMaxWait,
+		Timeout:             defaultAttemptTimeout,
+	}
+
+	if rps > 0 {
+		burst := int(rps)
+		if burst < 1 {
+			burst = 1
+		}
+		t.Limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+
+	if maxConcurrent > 0 {
+		t.Slots = make(chan struct{}, maxConcurrent)
+	}
+
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	op := describeRequest(req, body)
+
+	tflog.Debug(ctx, "Sending Fly API request", map[string]interface{}{
+		"operation": op.name,
+		"method":    req.Method,
+		"url":       req.URL.String(),
+	})
+	if op.variables != nil {
+		tflog.Trace(ctx, "Fly API request variables", map[string]interface{}{
+			"operation": op.name,
+			"variables": redact(op.variables),
+		})
+	}
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := t.roundTrip(req, body)
+		fields := map[string]interface{}{
+			"operation":  op.name,
+			"attempt":    attempt + 1,
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+
+		if err != nil {
+			fields["error"] = err.Error()
+			tflog.Debug(ctx, "Fly API request failed", fields)
+		} else {
+			fields["status"] = resp.StatusCode
+			// REST bodies, e.g. a long machine list, are only read for errors
+			// when the status says there are some
+			if op.graphql || resp.StatusCode >= 300 {
+				if errs := inspectErrors(resp); errs != nil {
+					fields["errors"] = errs
+				}
+			}
+			tflog.Debug(ctx, "Received Fly API response", fields)
+		}
+
+		if attempt >= t.MaxRetries || !shouldRetry(ctx, resp, err, op.idempotent) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+
+		tflog.Debug(ctx, "Retrying Fly API request", map[string]interface{}{
+			"operation": op.name,
+			"wait_ms":   wait.Milliseconds(),
+		})
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip sends a single attempt of req with a fresh copy of body
+func (t *Transport) roundTrip(req *http.Request, body []byte) (*http.Response, error) {
+	release, err := t.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if t.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+
+	r := req.Clone(ctx)
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	r.Header.Set("Authorization", "Bearer "+t.Token)
+
+	resp, err := t.UnderlyingTransport.RoundTrip(r)
+	if err != nil {
+		cancel()
+		release()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: func() {
+		cancel()
+		release()
+	}}
+
+	return resp, nil
+}
+
+// acquire blocks until the rate limiter and the concurrency limit both allow
+// another request. The returned func gives the concurrency slot back.
+func (t *Transport) acquire(ctx context.Context) (func(), error) {
+	if t.Slots != nil {
+		select {
+		case t.Slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if t.Slots != nil {
+			<-t.Slots
+		}
+	}
+
+	if t.Limiter != nil {
+		if err := t.Limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// backoff returns how long to wait before the next attempt, preferring the
+// server's Retry-After header when it sent one
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	limit := t.RetryMaxWait
+	if limit <= 0 {
+		limit = defaultRetryMaxWait
+	}
+
+	wait := retryBaseWait << attempt
+	if wait <= 0 || wait > limit {
+		wait = limit
+	}
+	// equal jitter: between half and all of the exponential step
+	wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+
+	if resp != nil {
+		if ra := parseRetryAfter(resp.Header.Get("Retry-After")); ra > wait {
+			wait = ra
+		}
+	}
+
+	if wait > limit {
+		wait = limit
+	}
+
+	return wait
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating. Rate
+// limited requests were never processed so they are always retried, other
+// failures only when repeating the request is harmless.
+func shouldRetry(ctx context.Context, resp *http.Response, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return idempotent
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500:
+		return idempotent
+	}
+
+	return false
+}
+
+// requestInfo is what the transport knows about an outgoing request
+type requestInfo struct {
+	// name is the GraphQL operation, e.g. "mutation createApp", or the
+	// method and path of a REST request
+	name       string
+	idempotent bool
+	variables  map[string]interface{}
+	// graphql is set for GraphQL requests, whose errors come back with a 200
+	graphql bool
+}
+
+// describeRequest names req and works out whether it can safely be sent more
+// than once
+func describeRequest(req *http.Request, body []byte) requestInfo {
+	info := requestInfo{name: req.Method + " " + req.URL.Path}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		info.idempotent = true
+		return info
+	case http.MethodPost:
+	default:
+		return info
+	}
+
+	var gql struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &gql); err != nil || gql.Query == "" {
+		return info
+	}
+
+	info.graphql = true
+	info.variables = gql.Variables
+
+	m := operationRe.FindStringSubmatch(strings.TrimSpace(gql.Query))
+	if m == nil {
+		return info
+	}
+
+	kind := m[1]
+	if kind == "" {
+		kind = "query"
+	}
+	info.name = kind + " " + m[2]
+
+	switch kind {
+	case "query":
+		info.idempotent = true
+	case "mutation":
+		info.idempotent = safeMutations[m[2]]
+	}
+
+	return info
+}
+
+// redactedKeys are variable names whose values never make it into the logs
+var redactedKeys = map[string]bool{
+	"value":    true,
+	"token":    true,
+	"password": true,
+}
+
+// redact returns a copy of v with the values of redactedKeys masked
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			if redactedKeys[strings.ToLower(k)] {
+				out[k] = "[REDACTED]"
+				continue
+			}
+			out[k] = redact(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = redact(val)
+		}
+		return out
+	}
+
+	return v
+}
+
+// inspectErrors returns the errors reported in a JSON response body, leaving
+// the body readable for the caller
+func inspectErrors(resp *http.Response) interface{} {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return err.Error()
+	}
+
+	var payload struct {
+		Errors interface{} `json:"errors"`
+		Error  interface{} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil
+	}
+
+	if payload.Errors != nil {
+		return payload.Errors
+	}
+
+	return payload.Error
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// cancelOnClose releases a per-attempt context and concurrency slot once the
+// response body has been consumed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+	once   sync.Once
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.once.Do(c.cancel)
+	return err
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// stringOneOf accepts only the given values
+type stringOneOf []string
+
+var _ validator.String = stringOneOf{}
+
+func (v stringOneOf) Description(_ context.Context) string {
+	return "value must be one of " + strings.Join(v, ", ")
+}
+
+func (v stringOneOf) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringOneOf) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, s := range v {
+		if req.ConfigValue.ValueString() == s {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid value",
+		fmt.Sprintf("Got %q, %s.", req.ConfigValue.ValueString(), v.Description(ctx)),
+	)
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &vmSizesDataSource{}
+
+func newVMSizesDataSource() datasource.DataSource {
+	return &vmSizesDataSource{}
+}
+
+type vmSizesDataSource struct {
+	client flyclient.Client
+}
+
+type vmSizesDataSourceModel struct {
+	VMSizes []vmSizeDataItemModel `tfsdk:"vm_sizes"`
+}
+
+type vmSizeDataItemModel struct {
+	Name        types.String  `tfsdk:"name"`
+	CPUCores    types.Float64 `tfsdk:"cpu_cores"`
+	CPUClass    types.String  `tfsdk:"cpu_class"`
+	MemoryMB    types.Int64   `tfsdk:"memory_mb"`
+	PriceMonth  types.Float64 `tfsdk:"price_month"`
+	PriceSecond types.Float64 `tfsdk:"price_second"`
+}
+
+func (d *vmSizesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vm_sizes"
+}
+
+func (d *vmSizesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fly VM sizes",
+
+		Attributes: map[string]schema.Attribute{
+			"vm_sizes": schema.ListNestedAttribute{
+				MarkdownDescription: "VM sizes, in the order Fly lists them",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Size name, e.g. `shared-cpu-1x`",
+							Computed:            true,
+						},
+						"cpu_cores": schema.Float64Attribute{
+							MarkdownDescription: "CPU cores",
+							Computed:            true,
+						},
+						"cpu_class": schema.StringAttribute{
+							MarkdownDescription: "CPU class",
+							Computed:            true,
+						},
+						"memory_mb": schema.Int64Attribute{
+							MarkdownDescription: "Memory in MB",
+							Computed:            true,
+						},
+						"price_month": schema.Float64Attribute{
+							MarkdownDescription: "Price per month in USD",
+							Computed:            true,
+						},
+						"price_second": schema.Float64Attribute{
+							MarkdownDescription: "Price per second in USD",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *vmSizesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *vmSizesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	sizes, err := d.client.GetVMSizes(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing VM sizes", err.Error())
+		return
+	}
+
+	model := vmSizesDataSourceModel{
+		VMSizes: make([]vmSizeDataItemModel, 0, len(sizes)),
+	}
+	for _, s := range sizes {
+		model.VMSizes = append(model.VMSizes, vmSizeDataItemModel{
+			Name:        types.StringValue(s.Name),
+			CPUCores:    types.Float64Value(float64(s.CPUCores)),
+			CPUClass:    types.StringValue(s.CPUClass),
+			MemoryMB:    types.Int64Value(int64(s.MemoryMB)),
+			PriceMonth:  types.Float64Value(float64(s.PriceMonth)),
+			PriceSecond: types.Float64Value(float64(s.PriceSecond)),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	snapshotWaitTimeout  = 2 * time.Minute
+	snapshotPollInterval = 2 * time.Second
+	// snapshotClockSkew is how much earlier than our request a new
+	// snapshot's created_at may be, to allow for our clock running ahead
+	snapshotClockSkew = 30 * time.Second
+)
+
+// snapshotLocks serializes snapshot creation per volume, keyed by app/volume,
+// so two creates can't both claim the same new snapshot
+var snapshotLocks sync.Map
+
+var (
+	_ resource.Resource              = &volumeSnapshotResource{}
+	_ resource.ResourceWithConfigure = &volumeSnapshotResource{}
+)
+
+type volumeSnapshotResource struct {
+	machines flyclient.Machines
+}
+
+func newVolumeSnapshotResource() resource.Resource {
+	return &volumeSnapshotResource{}
+}
+
+type volumeSnapshotResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	AppName   types.String `tfsdk:"app"`
+	VolumeID  types.String `tfsdk:"volume_id"`
+	Size      types.Int64  `tfsdk:"size"`
+	Digest    types.String `tfsdk:"digest"`
+	Status    types.String `tfsdk:"status"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+func (r *volumeSnapshotResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_volume_snapshot"
+}
+
+func (r *volumeSnapshotResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "On-demand snapshot of a Fly volume. Fly has no API to delete snapshots, so destroying " +
+			"this only removes it from state and the snapshot expires with the volume's snapshot retention.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Snapshot ID",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"app": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"volume_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the volume to snapshot",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"size": schema.Int64Attribute{
+				MarkdownDescription: "Snapshot size in bytes",
+				Computed:            true,
+			},
+			"digest": schema.StringAttribute{
+				MarkdownDescription: "Snapshot digest",
+				Computed:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Snapshot status",
+				Computed:            true,
+			},
+			"created_at": schema.StringAttribute{
+				MarkdownDescription: "Creation time",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *volumeSnapshotResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.machines = data.machines
+}
+
+func (r *volumeSnapshotResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var snapshot volumeSnapshotResourceModel
+
+	diags := req.Plan.Get(ctx, &snapshot)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	app, volumeID := snapshot.AppName.ValueString(), snapshot.VolumeID.ValueString()
+
+	lock, _ := snapshotLocks.LoadOrStore(app+"/"+volumeID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	existing, err := r.machines.ListVolumeSnapshots(ctx, app, volumeID)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing snapshots", err.Error())
+		return
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		seen[s.ID] = true
+	}
+
+	since := time.Now().Add(-snapshotClockSkew)
+	if err := r.machines.CreateVolumeSnapshot(ctx, app, volumeID); err != nil {
+		resp.Diagnostics.AddError("Query failed creating snapshot", err.Error())
+		return
+	}
+
+	// the API doesn't return the new snapshot, so wait for one we haven't
+	// seen before, created since the request, to be listed. If Fly takes a
+	// scheduled snapshot meanwhile, the newest of them is taken.
+	deadline := time.Now().Add(snapshotWaitTimeout)
+	for {
+		snapshots, err := r.machines.ListVolumeSnapshots(ctx, app, volumeID)
+		if err != nil {
+			resp.Diagnostics.AddError("Query failed listing snapshots", err.Error())
+			return
+		}
+
+		if s := newestSnapshotSince(snapshots, seen, since); s != nil {
+			snapshot.update(s)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &snapshot)...)
+			return
+		}
+
+		if time.Now().After(deadline) {
+			resp.Diagnostics.AddError(
+				"Snapshot not found",
+				fmt.Sprintf("The snapshot of volume %s was requested but didn't show up within %s.", volumeID, snapshotWaitTimeout),
+			)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			resp.Diagnostics.AddError("Snapshot not found", ctx.Err().Error())
+			return
+		case <-time.After(snapshotPollInterval):
+		}
+	}
+}
+
+func (r *volumeSnapshotResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var snapshot volumeSnapshotResourceModel
+
+	diags := req.State.Get(ctx, &snapshot)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snapshots, err := r.machines.ListVolumeSnapshots(ctx, snapshot.AppName.ValueString(), snapshot.VolumeID.ValueString())
+	if flyclient.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing snapshots", err.Error())
+		return
+	}
+
+	for i := range snapshots {
+		if snapshots[i].ID == snapshot.ID.ValueString() {
+			snapshot.update(&snapshots[i])
+			resp.Diagnostics.Append(resp.State.Set(ctx, &snapshot)...)
+			return
+		}
+	}
+
+	// expired
+	resp.State.RemoveResource(ctx)
+}
+
+func (r *volumeSnapshotResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+}
+
+func (r *volumeSnapshotResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// update copies the values Fly reports for s into the model
+func (m *volumeSnapshotResourceModel) update(s *flyclient.VolumeSnapshot) {
+	m.ID = types.StringValue(s.ID)
+	m.Size = types.Int64Value(s.Size)
+	m.Digest = types.StringValue(s.Digest)
+	m.Status = types.StringValue(s.Status)
+	m.CreatedAt = types.StringValue(s.CreatedAt)
+}
+
+// newestSnapshotSince returns the newest snapshot not in seen that was
+// created at or after since, or nil if there is none yet
+func newestSnapshotSince(snapshots []flyclient.VolumeSnapshot, seen map[string]bool, since time.Time) *flyclient.VolumeSnapshot {
+	var newest *flyclient.VolumeSnapshot
+	var newestAt time.Time
+
+	for i := range snapshots {
+		if seen[snapshots[i].ID] {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339Nano, snapshots[i].CreatedAt)
+		if err != nil || createdAt.Before(since) {
+			continue
+		}
+
+		if newest == nil || createdAt.After(newestAt) {
+			newest, newestAt = &snapshots[i], createdAt
+		}
+	}
+
+	return newest
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &volumeSnapshotsDataSource{}
+
+func newVolumeSnapshotsDataSource() datasource.DataSource {
+	return &volumeSnapshotsDataSource{}
+}
+
+type volumeSnapshotsDataSource struct {
+	machines flyclient.Machines
+}
+
+type volumeSnapshotsDataSourceModel struct {
+	AppName   types.String                  `tfsdk:"app"`
+	VolumeID  types.String                  `tfsdk:"volume_id"`
+	Snapshots []volumeSnapshotDataItemModel `tfsdk:"snapshots"`
+}
+
+type volumeSnapshotDataItemModel struct {
+	ID        types.String `tfsdk:"id"`
+	Size      types.Int64  `tfsdk:"size"`
+	Digest    types.String `tfsdk:"digest"`
+	Status    types.String `tfsdk:"status"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+func (d *volumeSnapshotsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_volume_snapshots"
+}
+
+func (d *volumeSnapshotsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Snapshots of a Fly volume, newest first",
+
+		Attributes: map[string]schema.Attribute{
+			"app": schema.StringAttribute{
+				MarkdownDescription: "App name",
+				Required:            true,
+			},
+			"volume_id": schema.StringAttribute{
+				MarkdownDescription: "Volume ID",
+				Required:            true,
+			},
+			"snapshots": schema.ListNestedAttribute{
+				MarkdownDescription: "Snapshots, newest first",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Snapshot ID",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "Snapshot size in bytes",
+							Computed:            true,
+						},
+						"digest": schema.StringAttribute{
+							MarkdownDescription: "Snapshot digest",
+							Computed:            true,
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "Snapshot status",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Creation time",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *volumeSnapshotsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.machines = data.machines
+}
+
+func (d *volumeSnapshotsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var model volumeSnapshotsDataSourceModel
+
+	diags := req.Config.Get(ctx, &model)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snapshots, err := d.machines.ListVolumeSnapshots(ctx, model.AppName.ValueString(), model.VolumeID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed listing snapshots", err.Error())
+		return
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		ti, erri := time.Parse(time.RFC3339Nano, snapshots[i].CreatedAt)
+		tj, errj := time.Parse(time.RFC3339Nano, snapshots[j].CreatedAt)
+		if erri != nil || errj != nil {
+			return snapshots[i].CreatedAt > snapshots[j].CreatedAt
+		}
+
+		return ti.After(tj)
+	})
+
+	model.Snapshots = make([]volumeSnapshotDataItemModel, 0, len(snapshots))
+	for _, s := range snapshots {
+		model.Snapshots = append(model.Snapshots, volumeSnapshotDataItemModel{
+			ID:        types.StringValue(s.ID),
+			Size:      types.Int64Value(s.Size),
+			Digest:    types.StringValue(s.Digest),
+			Status:    types.StringValue(s.Status),
+			CreatedAt: types.StringValue(s.CreatedAt),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
@@ -2,24 +2,30 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/getenv/terraform-provider-fly/internal/flyclient"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	fly "github.com/superfly/flyctl/api"
-	"github.com/superfly/graphql"
 )
 
 var (
 	_ resource.Resource                = &volumesResource{}
 	_ resource.ResourceWithConfigure   = &volumesResource{}
+	_ resource.ResourceWithModifyPlan  = &volumesResource{}
 	_ resource.ResourceWithImportState = &volumesResource{}
 )
 
 type volumesResource struct {
-	client *graphql.Client
+	client   flyclient.Client
+	machines flyclient.Machines
+	regions  *regionCache
 }
 
 func newVolumesResource() resource.Resource {
@@ -27,10 +33,19 @@ func newVolumesResource() resource.Resource {
 }
 
 type volumesResourceModel struct {
-	AppName types.String `tfsdk:"app"`
-	Name    types.String `tfsdk:"name"`
-	Region  types.String `tfsdk:"region"`
-	SizeGB  types.Int64  `tfsdk:"sizegb"`
+	ID                types.String `tfsdk:"id"`
+	AppName           types.String `tfsdk:"app"`
+	Name              types.String `tfsdk:"name"`
+	Region            types.String `tfsdk:"region"`
+	SizeGB            types.Int64  `tfsdk:"sizegb"`
+	State             types.String `tfsdk:"state"`
+	AttachedMachineID types.String `tfsdk:"attached_machine_id"`
+
+	Encrypted         types.Bool   `tfsdk:"encrypted"`
+	SnapshotRetention types.Int64  `tfsdk:"snapshot_retention"`
+	RequireUniqueZone types.Bool   `tfsdk:"require_unique_zone"`
+	SourceVolumeID    types.String `tfsdk:"source_volume_id"`
+	SnapshotID        types.String `tfsdk:"snapshot_id"`
 }
 
 func (r *volumesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -42,131 +57,307 @@ func (r *volumesResource) Schema(_ context.Context, req resource.SchemaRequest,
 		MarkdownDescription: "Fly Volumes",
 
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Volume ID",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"app": schema.StringAttribute{
 				MarkdownDescription: "App name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"name": schema.StringAttribute{
 				MarkdownDescription: "Volume name",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"region": schema.StringAttribute{
 				MarkdownDescription: "Deployment region",
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"sizegb": schema.Int64Attribute{
-				MarkdownDescription: "Volume size in GB",
+				MarkdownDescription: "Volume size in GB. Growing it extends the volume in place, shrinking it replaces the volume",
 				Required:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(
+						volumeShrinks,
+						"Volumes can't be shrunk so a smaller size replaces the volume",
+						"Volumes can't be shrunk so a smaller size replaces the volume",
+					),
+				},
+			},
+			"state": schema.StringAttribute{
+				MarkdownDescription: "Volume state",
+				Computed:            true,
+			},
+			"attached_machine_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the machine the volume is mounted on, if any",
+				Computed:            true,
+			},
+			"encrypted": schema.BoolAttribute{
+				MarkdownDescription: "Encrypt the volume at rest. Defaults to true",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"snapshot_retention": schema.Int64Attribute{
+				MarkdownDescription: "Days daily snapshots are kept for. Defaults to Fly's retention",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"require_unique_zone": schema.BoolAttribute{
+				MarkdownDescription: "Place the volume on a host no other volume of the app is on. Only used on create",
+				Optional:            true,
+			},
+			"source_volume_id": schema.StringAttribute{
+				MarkdownDescription: "Create the volume as a copy of this volume. Changing it replaces the volume",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"snapshot_id": schema.StringAttribute{
+				MarkdownDescription: "Restore the volume from this snapshot. Changing it replaces the volume",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 		},
 	}
 }
 
+// volumeShrinks requires replacement when sizegb is lowered
+func volumeShrinks(_ context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	resp.RequiresReplace = req.PlanValue.ValueInt64() < req.StateValue.ValueInt64()
+}
+
 func (r *volumesResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*graphql.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *graphql.Client, got: %T.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.machines = data.machines
+	r.regions = data.regions
+}
+
+func (r *volumesResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(validateRegionPlan(ctx, r.regions, req, "region")...)
 }
 
 func (r *volumesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var volume volumesResourceModel
 
 	diags := req.Plan.Get(ctx, &volume)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	input := flyclient.CreateVolumeRequest{
+		Name:              volume.Name.ValueString(),
+		Region:            volume.Region.ValueString(),
+		SizeGB:            int(volume.SizeGB.ValueInt64()),
+		Encrypted:         boolPointer(volume.Encrypted),
+		RequireUniqueZone: boolPointer(volume.RequireUniqueZone),
+		SnapshotRetention: intPointer(volume.SnapshotRetention),
+		SourceVolumeID:    stringPointer(volume.SourceVolumeID),
+		SnapshotID:        stringPointer(volume.SnapshotID),
+	}
 
-	data, err := json.Marshal(diags)
+	v, err := r.machines.CreateVolume(ctx, volume.AppName.ValueString(), input)
 	if err != nil {
-		resp.Diagnostics.AddError("unmarshall error", err.Error())
+		resp.Diagnostics.AddError("Query failed creating a volume to the app", err.Error())
+		return
 	}
 
+	volume.update(v)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &volume)...)
+}
+
+func (r *volumesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var volume volumesResourceModel
+	diags := req.State.Get(ctx, &volume)
 	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	v, err := r.find(ctx, &volume)
+	if flyclient.IsNotFound(err) || (err == nil && volumeGone(v)) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed fetching Read", err.Error())
+		return
+	}
+
+	volume.update(v)
+	resp.Diagnostics.Append(resp.State.Set(ctx, volume)...)
+}
 
+func (r *volumesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state volumesResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
-		resp.Diagnostics.AddError("Query failed appending volume details to diagnostics", string(data))
 		return
 	}
 
-	query := `
-	mutation($input: CreateVolumeInput!) {
-		createVolume(input: $input) {
-			app {
-				name
-			}
+	app, id := state.AppName.ValueString(), state.ID.ValueString()
+
+	if n := plan.SnapshotRetention; !n.IsNull() && !n.IsUnknown() && !n.Equal(state.SnapshotRetention) {
+		input := flyclient.UpdateVolumeRequest{SnapshotRetention: intPointer(n)}
+		if _, err := r.machines.UpdateVolume(ctx, app, id, input); err != nil {
+			resp.Diagnostics.AddError("Query failed updating volume", err.Error())
+			return
 		}
 	}
-	`
-	resp.Diagnostics.AddWarning("App name", volume.AppName.ValueString()+"_data_")
 
-	createVolMutationInput := fly.CreateVolumeInput{
-		AppID:  volume.AppName.ValueString(),
-		Name:   volume.Name.ValueString(),
-		Region: volume.Region.ValueString(),
-		SizeGb: int(volume.SizeGB.ValueInt64()),
+	// the other attributes require replacement, so only a bigger size is left
+	if plan.SizeGB.ValueInt64() != state.SizeGB.ValueInt64() {
+		if _, err := r.machines.ExtendVolume(ctx, app, id, int(plan.SizeGB.ValueInt64())); err != nil {
+			resp.Diagnostics.AddError("Query failed extending volume", err.Error())
+			return
+		}
 	}
 
-	grq := graphql.NewRequest(query)
-	grq.Var("input", createVolMutationInput)
-
-	var ff fly.Query
-	if err := r.client.Run(context.Background(), grq, &ff); err != nil {
-		resp.Diagnostics.AddError("Query failed creating a volume to the app", err.Error())
+	v, err := r.machines.GetVolume(ctx, app, id)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed updating volume", err.Error())
+		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &volume)...)
+	plan.update(v)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func (r *volumesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+func (r *volumesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var volume volumesResourceModel
+
 	diags := req.State.Get(ctx, &volume)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	q := `
-	query($appName: String!) {
-		app(name: $appName) {
-			volumes {
-				nodes {
-					id
-					name
-					state
-				}
-			}
-		}
+	err := r.machines.DeleteVolume(ctx, volume.AppName.ValueString(), volume.ID.ValueString())
+	if err != nil && !flyclient.IsNotFound(err) {
+		resp.Diagnostics.AddError("Query failed deleting volume", err.Error())
+	}
+}
+
+// ImportState imports a volume by its ID, e.g. `vol_1234`. The Machines API
+// needs the volume's app too, so it is looked up over GraphQL.
+func (r *volumesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	app, err := r.client.LookupVolumeApp(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Query failed looking up volume", err.Error())
+		return
 	}
-	`
 
-	grq := graphql.NewRequest(q)
-	grq.Var("appName", volume.AppName.ValueString())
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("app"), app)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
 
-	var fq fly.Query
-	if err := r.client.Run(context.Background(), grq, &fq); err != nil {
-		resp.Diagnostics.AddError("Query failed fetching Read", err.Error())
+// find looks the volume up by ID, or by name for state written before the
+// ID was recorded
+func (r *volumesResource) find(ctx context.Context, volume *volumesResourceModel) (*flyclient.Volume, error) {
+	app := volume.AppName.ValueString()
+	if id := volume.ID.ValueString(); id != "" {
+		return r.machines.GetVolume(ctx, app, id)
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, volume)...)
+	volumes, err := r.machines.ListVolumes(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range volumes {
+		if volumes[i].Name == volume.Name.ValueString() && !volumeGone(&volumes[i]) {
+			return &volumes[i], nil
+		}
+	}
+
+	return &flyclient.Volume{}, nil
 }
 
-func (r *volumesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+// volumeGone reports whether v is missing or being destroyed
+func volumeGone(v *flyclient.Volume) bool {
+	switch v.State {
+	case "destroying", "destroyed", "pending_destroy":
+		return true
+	}
 
+	return v.ID == ""
 }
 
-func (r *volumesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+// update copies the values Fly reports for v into the model
+func (m *volumesResourceModel) update(v *flyclient.Volume) {
+	m.ID = types.StringValue(v.ID)
+	m.Name = types.StringValue(v.Name)
+	m.Region = types.StringValue(v.Region)
+	m.SizeGB = types.Int64Value(int64(v.SizeGB))
+	m.State = types.StringValue(v.State)
+	m.Encrypted = types.BoolValue(v.Encrypted)
+	m.SnapshotRetention = types.Int64Value(int64(v.SnapshotRetention))
+
+	m.AttachedMachineID = types.StringNull()
+	if v.AttachedMachineID != "" {
+		m.AttachedMachineID = types.StringValue(v.AttachedMachineID)
+	}
+}
 
+// intPointer returns nil for a null value so the API applies its default
+func intPointer(v types.Int64) *int {
+	if v.IsNull() || v.IsUnknown() {
+		return nil
+	}
+
+	i := int(v.ValueInt64())
+	return &i
 }
 
-func (r *volumesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+// stringPointer returns nil for a null or empty value
+func stringPointer(v types.String) *string {
+	if v.ValueString() == "" {
+		return nil
+	}
+
+	s := v.ValueString()
+	return &s
 }